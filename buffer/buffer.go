@@ -39,6 +39,37 @@ func NewBuffer(r readAtSeeker) *Buffer {
 	}
 }
 
+// NewPatternBuffer creates a new buffer of the size filled with the pattern.
+func NewPatternBuffer(pattern []byte, size int64) *Buffer {
+	if len(pattern) == 1 {
+		return newSizedBuffer(constReader(pattern[0]), size)
+	}
+	return newSizedBuffer(patternReader(pattern), size)
+}
+
+// NewRandomBuffer creates a new buffer of the size filled with random bytes.
+func NewRandomBuffer(seed uint64, size int64) *Buffer {
+	return newSizedBuffer(randomReader(seed), size)
+}
+
+// NewIncrementBuffer creates a new buffer of the size filled with
+// incrementing bytes starting from the byte.
+func NewIncrementBuffer(start byte, size int64) *Buffer {
+	return newSizedBuffer(incrementReader(start), size)
+}
+
+func newSizedBuffer(r readAtSeeker, size int64) *Buffer {
+	b := &Buffer{
+		rrs: []readerRange{
+			{r: r, min: 0, max: size, diff: 0},
+			{r: newBytesReader(nil), min: size, max: math.MaxInt64, diff: -size},
+		},
+		mu: new(sync.Mutex),
+	}
+	b.cleanup()
+	return b
+}
+
 // Read reads bytes.
 func (b *Buffer) Read(p []byte) (int, error) {
 	b.mu.Lock()
@@ -140,7 +171,7 @@ func (b *Buffer) EditedIndices() []int64 {
 	eis := make([]int64, 0, len(b.rrs))
 	for _, rr := range b.rrs {
 		switch rr.r.(type) {
-		case *bytesReader, constReader:
+		case *bytesReader, constReader, patternReader, randomReader, incrementReader:
 			// constReader can be adjacent to another bytesReader or constReader.
 			if l := len(eis); l > 0 && eis[l-1] == rr.min {
 				eis[l-1] = rr.max
@@ -206,6 +237,18 @@ func (b *Buffer) Clone() *Buffer {
 	return newBuf
 }
 
+// Refers reports whether the buffer reads from the reader.
+func (b *Buffer) Refers(r io.ReaderAt) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, rr := range b.rrs {
+		if rr.r == r {
+			return true
+		}
+	}
+	return false
+}
+
 // Copy a part of the buffer.
 func (b *Buffer) Copy(start, end int64) *Buffer {
 	b.mu.Lock()
@@ -316,6 +359,19 @@ func (b *Buffer) Paste(offset int64, c *Buffer) {
 	b.cleanup()
 }
 
+// Overwrite replaces the bytes from the offset with a buffer.
+// The buffer is extended if the bytes exceed the end of the buffer.
+func (b *Buffer) Overwrite(offset int64, c *Buffer) {
+	l, _ := c.Len()
+	if l == 0 {
+		return
+	}
+	if n, _ := b.Len(); offset < n {
+		b.Cut(offset, mathutil.MinInt64(offset+l, n))
+	}
+	b.Paste(offset, c)
+}
+
 // Insert inserts a byte at the specific position.
 func (b *Buffer) Insert(offset int64, c byte) {
 	b.mu.Lock()
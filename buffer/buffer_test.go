@@ -235,6 +235,25 @@ func TestBufferCopy(t *testing.T) {
 	}
 }
 
+func TestBufferRefers(t *testing.T) {
+	r1, r2 := strings.NewReader("0123456789"), strings.NewReader("abcdef")
+	b := NewBuffer(r1)
+	if !b.Refers(r1) {
+		t.Errorf("buffer should refer to the reader")
+	}
+	if b.Refers(r2) {
+		t.Errorf("buffer should not refer to the reader")
+	}
+	b.Paste(5, NewBuffer(r2).Copy(0, 3))
+	if !b.Refers(r2) {
+		t.Errorf("buffer should refer to the pasted reader")
+	}
+	b.Cut(5, 8)
+	if b.Refers(r2) {
+		t.Errorf("buffer should not refer to the cut reader")
+	}
+}
+
 func TestBufferCut(t *testing.T) {
 	b := NewBuffer(strings.NewReader("0123456789abcdef"))
 	b.Replace(3, 0x41)
@@ -322,6 +341,53 @@ func TestBufferPaste(t *testing.T) {
 	}
 }
 
+func TestBufferOverwrite(t *testing.T) {
+	b := NewBuffer(strings.NewReader("0123456789abcdef"))
+	tests := []struct {
+		offset   int64
+		c        *Buffer
+		expected string
+		eis      []int64
+	}{
+		{2, NewPatternBuffer([]byte("xyz"), 7), "01xyzxyzx9abcdef", []int64{2, 9}},
+		{4, NewPatternBuffer([]byte("-"), 3), "01xy---zx9abcdef", []int64{2, 9}},
+		{10, NewIncrementBuffer(0xfe, 4), "01xy---zx9\xfe\xff\x00\x01ef", []int64{2, 9, 10, 14}},
+		{14, NewPatternBuffer([]byte("AB"), 5), "01xy---zx9\xfe\xff\x00\x01ABABA", []int64{2, 9, 10, 19}},
+		{0, NewPatternBuffer([]byte("AB"), 0), "01xy---zx9\xfe\xff\x00\x01ABABA", []int64{2, 9, 10, 19}},
+	}
+	for _, test := range tests {
+		b.Overwrite(test.offset, test.c)
+		p := make([]byte, 30)
+		n, _ := b.ReadAt(p, 0)
+		if string(p[:n]) != test.expected {
+			t.Errorf("p should be %q but got: %q", test.expected, string(p[:n]))
+		}
+		if eis := b.EditedIndices(); !reflect.DeepEqual(eis, test.eis) {
+			t.Errorf("edited indices should be %v but got: %v", test.eis, eis)
+		}
+	}
+	if l, _ := b.Len(); l != 19 {
+		t.Errorf("l should be %d but got: %d", 19, l)
+	}
+}
+
+func TestBufferRandom(t *testing.T) {
+	b := NewRandomBuffer(42, 100)
+	if l, _ := b.Len(); l != 100 {
+		t.Errorf("l should be %d but got: %d", 100, l)
+	}
+	p, q := make([]byte, 100), make([]byte, 50)
+	_, _ = b.ReadAt(p, 0)
+	_, _ = b.ReadAt(q, 37)
+	if string(p[37:87]) != string(q) {
+		t.Errorf("random bytes should be determined by the offset but got: %v and %v", p[37:87], q)
+	}
+	_, _ = NewRandomBuffer(43, 100).ReadAt(q, 37)
+	if string(p[37:87]) == string(q) {
+		t.Errorf("random bytes should be determined by the seed but got: %v", q)
+	}
+}
+
 func TestBufferInsert(t *testing.T) {
 	b := NewBuffer(strings.NewReader("0123456789abcdef"))
 
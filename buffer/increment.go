@@ -0,0 +1,21 @@
+package buffer
+
+type incrementReader byte
+
+// Read implements the io.Reader interface.
+func (r incrementReader) Read(b []byte) (int, error) {
+	return r.ReadAt(b, 0)
+}
+
+// Seek implements the io.Seeker interface.
+func (r incrementReader) Seek(offset int64, whence int) (int64, error) {
+	return 0, nil
+}
+
+// ReadAt implements the io.ReaderAt interface.
+func (r incrementReader) ReadAt(b []byte, offset int64) (int, error) {
+	for i := range b {
+		b[i] = byte(r) + byte(offset+int64(i))
+	}
+	return len(b), nil
+}
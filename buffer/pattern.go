@@ -0,0 +1,24 @@
+package buffer
+
+type patternReader []byte
+
+// Read implements the io.Reader interface.
+func (r patternReader) Read(b []byte) (int, error) {
+	return r.ReadAt(b, 0)
+}
+
+// Seek implements the io.Seeker interface.
+func (r patternReader) Seek(offset int64, whence int) (int64, error) {
+	return 0, nil
+}
+
+// ReadAt implements the io.ReaderAt interface.
+func (r patternReader) ReadAt(b []byte, offset int64) (int, error) {
+	for i, j := 0, int(offset%int64(len(r))); i < len(b); i++ {
+		b[i] = r[j]
+		if j++; j == len(r) {
+			j = 0
+		}
+	}
+	return len(b), nil
+}
@@ -0,0 +1,35 @@
+package buffer
+
+// randomReader generates pseudo random bytes determined by the seed and
+// the offset, so that the same bytes are read from the same offset.
+type randomReader uint64
+
+// Read implements the io.Reader interface.
+func (r randomReader) Read(b []byte) (int, error) {
+	return r.ReadAt(b, 0)
+}
+
+// Seek implements the io.Seeker interface.
+func (r randomReader) Seek(offset int64, whence int) (int64, error) {
+	return 0, nil
+}
+
+// ReadAt implements the io.ReaderAt interface.
+func (r randomReader) ReadAt(b []byte, offset int64) (int, error) {
+	var x uint64
+	for i := range b {
+		j := uint64(offset + int64(i))
+		if i == 0 || j%8 == 0 {
+			x = splitmix64(uint64(r) + j/8)
+		}
+		b[i] = byte(x >> (j % 8 * 8))
+	}
+	return len(b), nil
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
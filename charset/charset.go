@@ -0,0 +1,120 @@
+package charset
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// Names is the list of the supported encodings.
+var Names = []string{
+	"utf-8", "utf-16le", "utf-16be", "latin1", "cp1252", "cp437", "ebcdic", "shift-jis",
+}
+
+var charmaps = map[string]*charmap.Charmap{
+	"latin1": charmap.ISO8859_1,
+	"cp1252": charmap.Windows1252,
+	"cp437":  charmap.CodePage437,
+	"ebcdic": charmap.CodePage037,
+}
+
+// UnitSize returns the size of the code unit of the encoding. The characters
+// of the encoding start at the offsets of the multiples of the unit size.
+func UnitSize(name string) int {
+	switch name {
+	case "utf-16le", "utf-16be":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Decode decodes the first character of the bytes in the encoding and
+// returns the character and its size. It returns utf8.RuneError and the
+// unit size if the bytes are invalid or incomplete.
+func Decode(name string, bs []byte) (rune, int) {
+	if len(bs) == 0 {
+		return utf8.RuneError, 0
+	}
+	switch name {
+	case "utf-16le", "utf-16be":
+		if len(bs) < 2 {
+			return utf8.RuneError, 1
+		}
+		r1 := decodeUTF16(name, bs)
+		if !utf16.IsSurrogate(r1) {
+			return r1, 2
+		}
+		if len(bs) < 4 {
+			return utf8.RuneError, 2
+		}
+		if r := utf16.DecodeRune(r1, decodeUTF16(name, bs[2:])); r != utf8.RuneError {
+			return r, 4
+		}
+		return utf8.RuneError, 2
+	case "shift-jis":
+		n := 1
+		if b := bs[0]; 0x81 <= b && b <= 0x9f || 0xe0 <= b && b <= 0xfc {
+			n = 2
+		}
+		if len(bs) < n {
+			return utf8.RuneError, 1
+		}
+		xs, err := japanese.ShiftJIS.NewDecoder().Bytes(bs[:n])
+		if err != nil {
+			return utf8.RuneError, 1
+		}
+		r, size := utf8.DecodeRune(xs)
+		if r == utf8.RuneError || size != len(xs) {
+			return utf8.RuneError, 1
+		}
+		return r, n
+	default:
+		if m, ok := charmaps[name]; ok {
+			return m.DecodeByte(bs[0]), 1
+		}
+		return utf8.DecodeRune(bs)
+	}
+}
+
+func decodeUTF16(name string, bs []byte) rune {
+	if name == "utf-16le" {
+		return rune(bs[0]) | rune(bs[1])<<8
+	}
+	return rune(bs[0])<<8 | rune(bs[1])
+}
+
+// Encode encodes the character in the encoding.
+func Encode(name string, r rune) ([]byte, error) {
+	switch name {
+	case "utf-16le", "utf-16be":
+		us := utf16.Encode([]rune{r})
+		bs := make([]byte, 0, 2*len(us))
+		for _, u := range us {
+			if name == "utf-16le" {
+				bs = append(bs, byte(u), byte(u>>8))
+			} else {
+				bs = append(bs, byte(u>>8), byte(u))
+			}
+		}
+		return bs, nil
+	case "shift-jis":
+		bs, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(string(r)))
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %q in %s", r, name)
+		}
+		return bs, nil
+	default:
+		if m, ok := charmaps[name]; ok {
+			b, ok := m.EncodeRune(r)
+			if !ok {
+				return nil, fmt.Errorf("cannot encode %q in %s", r, name)
+			}
+			return []byte{b}, nil
+		}
+		return []byte(string(r)), nil
+	}
+}
@@ -0,0 +1,70 @@
+package charset
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDecode(t *testing.T) {
+	for _, testCase := range []struct {
+		name string
+		bs   []byte
+		r    rune
+		size int
+	}{
+		{"utf-8", []byte("A"), 'A', 1},
+		{"utf-8", []byte("あx"), 'あ', 3},
+		{"utf-8", []byte{0xe3, 0x81}, utf8.RuneError, 1},
+		{"utf-16le", []byte{0x41, 0x00}, 'A', 2},
+		{"utf-16be", []byte{0x30, 0x42}, 'あ', 2},
+		{"utf-16le", []byte{0x3d, 0xd8, 0x00, 0xde}, '\U0001f600', 4},
+		{"utf-16le", []byte{0x3d, 0xd8, 0x41, 0x00}, utf8.RuneError, 2},
+		{"utf-16be", []byte{0x30}, utf8.RuneError, 1},
+		{"latin1", []byte{0xe9}, 'é', 1},
+		{"cp1252", []byte{0x80}, '€', 1},
+		{"cp437", []byte{0xdb}, '█', 1},
+		{"ebcdic", []byte{0xc1, 0xc2}, 'A', 1},
+		{"shift-jis", []byte{0x82, 0xa0}, 'あ', 2},
+		{"shift-jis", []byte{0xb1}, 'ｱ', 1},
+		{"shift-jis", []byte{0x82}, utf8.RuneError, 1},
+	} {
+		r, size := Decode(testCase.name, testCase.bs)
+		if r != testCase.r || size != testCase.size {
+			t.Errorf("Decode(%q, % x) should be %q, %d but got %q, %d",
+				testCase.name, testCase.bs, testCase.r, testCase.size, r, size)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		r        rune
+		expected []byte
+		err      string
+	}{
+		{"utf-8", 'あ', []byte{0xe3, 0x81, 0x82}, ""},
+		{"utf-16le", 'あ', []byte{0x42, 0x30}, ""},
+		{"utf-16be", '\U0001f600', []byte{0xd8, 0x3d, 0xde, 0x00}, ""},
+		{"latin1", 'é', []byte{0xe9}, ""},
+		{"latin1", 'あ', nil, "cannot encode 'あ' in latin1"},
+		{"ebcdic", 'A', []byte{0xc1}, ""},
+		{"shift-jis", 'あ', []byte{0x82, 0xa0}, ""},
+		{"shift-jis", '\U0001f600', nil, "cannot encode '😀' in shift-jis"},
+	} {
+		bs, err := Encode(testCase.name, testCase.r)
+		if testCase.err != "" {
+			if err == nil || err.Error() != testCase.err {
+				t.Errorf("Encode(%q, %q) should return error %q but got %v",
+					testCase.name, testCase.r, testCase.err, err)
+			}
+		} else if err != nil {
+			t.Errorf("Encode(%q, %q) should not return error but got %v", testCase.name, testCase.r, err)
+		}
+		if !reflect.DeepEqual(bs, testCase.expected) {
+			t.Errorf("Encode(%q, %q) should be % x but got % x",
+				testCase.name, testCase.r, testCase.expected, bs)
+		}
+	}
+}
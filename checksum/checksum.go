@@ -0,0 +1,40 @@
+package checksum
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"hash/adler32"
+	"hash/crc32"
+)
+
+// Names is the list of the supported algorithms.
+var Names = []string{"md5", "sha1", "sha256", "sha512", "crc32", "crc16", "adler32", "xxhash"}
+
+// New returns a new hash of the algorithm. The sums of the checksums are in
+// big-endian order.
+func New(name string) (hash.Hash, error) {
+	switch name {
+	case "md5":
+		return md5.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	case "crc32":
+		return crc32.NewIEEE(), nil
+	case "crc16":
+		return newCRC16(), nil
+	case "adler32":
+		return adler32.New(), nil
+	case "xxhash":
+		return newXXHash(), nil
+	default:
+		return nil, fmt.Errorf("unknown algorithm: %s", name)
+	}
+}
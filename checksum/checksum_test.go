@@ -0,0 +1,65 @@
+package checksum
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	for _, testCase := range []struct {
+		name, input, expected string
+	}{
+		{"md5", "", "d41d8cd98f00b204e9800998ecf8427e"},
+		{"sha1", "abc", "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{"sha256", "abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"crc32", "123456789", "cbf43926"},
+		{"crc16", "123456789", "bb3d"},
+		{"crc16", "", "0000"},
+		{"adler32", "Wikipedia", "11e60398"},
+		{"xxhash", "", "ef46db3751d8e999"},
+		{"xxhash", "a", "d24ec4f1a98c6e5b"},
+		{"xxhash", "abc", "44bc2cf5ad770999"},
+		{"xxhash", "Nobody inspects the spammish repetition", "fbcea83c8a378bf1"},
+	} {
+		h, err := New(testCase.name)
+		if err != nil {
+			t.Fatalf("New(%q) should not return error but got %v", testCase.name, err)
+		}
+		if _, err := h.Write([]byte(testCase.input)); err != nil {
+			t.Fatal(err)
+		}
+		sum := h.Sum(nil)
+		if got := hex.EncodeToString(sum); got != testCase.expected {
+			t.Errorf("%s(%q) should be %s but got %s", testCase.name, testCase.input, testCase.expected, got)
+		}
+		if len(sum) != h.Size() {
+			t.Errorf("size of %s should be %d but got %d", testCase.name, len(sum), h.Size())
+		}
+	}
+	if _, err := New("sha3"); err == nil || err.Error() != "unknown algorithm: sha3" {
+		t.Errorf("New should return error for unknown algorithm but got %v", err)
+	}
+}
+
+func TestWriteChunks(t *testing.T) {
+	input := []byte(strings.Repeat("0123456789abcdef", 100))
+	for _, name := range Names {
+		h, _ := New(name)
+		h.Write(input)
+		expected := hex.EncodeToString(h.Sum(nil))
+		for _, size := range []int{1, 7, 31, 32, 33, 100} {
+			h.Reset()
+			for i := 0; i < len(input); i += size {
+				end := i + size
+				if end > len(input) {
+					end = len(input)
+				}
+				h.Write(input[i:end])
+			}
+			if got := hex.EncodeToString(h.Sum(nil)); got != expected {
+				t.Errorf("%s should be %s on writing in chunks of %d bytes but got %s", name, expected, size, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package checksum
+
+import "hash"
+
+// crc16Table is the table of CRC-16/ARC, the reversed polynomial 0xa001.
+var crc16Table = func() *[256]uint16 {
+	var table [256]uint16
+	for i := range table {
+		crc := uint16(i)
+		for j := 0; j < 8; j++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xa001
+			} else {
+				crc >>= 1
+			}
+		}
+		table[i] = crc
+	}
+	return &table
+}()
+
+// crc16 computes CRC-16/ARC, which is also known as CRC-16/IBM.
+type crc16 uint16
+
+func newCRC16() hash.Hash {
+	return new(crc16)
+}
+
+func (c *crc16) Write(p []byte) (int, error) {
+	crc := uint16(*c)
+	for _, b := range p {
+		crc = crc>>8 ^ crc16Table[byte(crc)^b]
+	}
+	*c = crc16(crc)
+	return len(p), nil
+}
+
+func (c *crc16) Sum(b []byte) []byte {
+	return append(b, byte(*c>>8), byte(*c))
+}
+
+func (c *crc16) Reset() {
+	*c = 0
+}
+
+func (*crc16) Size() int {
+	return 2
+}
+
+func (*crc16) BlockSize() int {
+	return 1
+}
@@ -0,0 +1,112 @@
+package checksum
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// the primes are variables to let the arithmetic wrap around
+var (
+	prime64n1 uint64 = 11400714785074694791
+	prime64n2 uint64 = 14029467366897019727
+	prime64n3 uint64 = 1609587929392839161
+	prime64n4 uint64 = 9650029242287828579
+	prime64n5 uint64 = 2870177450012600261
+)
+
+// xxhash computes XXH64 with the zero seed.
+type xxhash struct {
+	v     [4]uint64
+	total uint64
+	mem   [32]byte
+	n     int
+}
+
+func newXXHash() hash.Hash {
+	x := new(xxhash)
+	x.Reset()
+	return x
+}
+
+func (x *xxhash) Reset() {
+	x.v = [4]uint64{prime64n1 + prime64n2, prime64n2, 0, -prime64n1}
+	x.total, x.n = 0, 0
+}
+
+func xxhashRound(acc, input uint64) uint64 {
+	return bits.RotateLeft64(acc+input*prime64n2, 31) * prime64n1
+}
+
+func xxhashMergeRound(acc, v uint64) uint64 {
+	return (acc^xxhashRound(0, v))*prime64n1 + prime64n4
+}
+
+func (x *xxhash) Write(p []byte) (int, error) {
+	n := len(p)
+	x.total += uint64(n)
+	if x.n+len(p) < 32 {
+		x.n += copy(x.mem[x.n:], p)
+		return n, nil
+	}
+	if x.n > 0 {
+		p = p[copy(x.mem[x.n:], p):]
+		x.block(x.mem[:])
+		x.n = 0
+	}
+	for ; len(p) >= 32; p = p[32:] {
+		x.block(p)
+	}
+	x.n = copy(x.mem[:], p)
+	return n, nil
+}
+
+func (x *xxhash) block(p []byte) {
+	for i := range x.v {
+		x.v[i] = xxhashRound(x.v[i], binary.LittleEndian.Uint64(p[i*8:]))
+	}
+}
+
+func (x *xxhash) Sum(b []byte) []byte {
+	var h uint64
+	if x.total >= 32 {
+		h = bits.RotateLeft64(x.v[0], 1) + bits.RotateLeft64(x.v[1], 7) +
+			bits.RotateLeft64(x.v[2], 12) + bits.RotateLeft64(x.v[3], 18)
+		for _, v := range x.v {
+			h = xxhashMergeRound(h, v)
+		}
+	} else {
+		h = prime64n5
+	}
+	h += x.total
+	p := x.mem[:x.n]
+	for ; len(p) >= 8; p = p[8:] {
+		h ^= xxhashRound(0, binary.LittleEndian.Uint64(p))
+		h = bits.RotateLeft64(h, 27)*prime64n1 + prime64n4
+	}
+	if len(p) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(p)) * prime64n1
+		h = bits.RotateLeft64(h, 23)*prime64n2 + prime64n3
+		p = p[4:]
+	}
+	for _, c := range p {
+		h ^= uint64(c) * prime64n5
+		h = bits.RotateLeft64(h, 11) * prime64n1
+	}
+	h ^= h >> 33
+	h *= prime64n2
+	h ^= h >> 29
+	h *= prime64n3
+	h ^= h >> 32
+	var bs [8]byte
+	binary.BigEndian.PutUint64(bs[:], h)
+	return append(b, bs[:]...)
+}
+
+func (*xxhash) Size() int {
+	return 8
+}
+
+func (*xxhash) BlockSize() int {
+	return 32
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 
 	"github.com/itchyny/bed/cmdline"
@@ -38,13 +39,18 @@ Version: %s (rev: %s/%s)
 
 Synopsis:
   %% %[1]s file
+  %% %[1]s -S session
+  %% %[1]s -i infofile file
 
 Options:
 `, name, version, revision, runtime.Version())
 		fs.PrintDefaults()
 	}
 	var showVersion bool
+	var session, info string
 	fs.BoolVar(&showVersion, "version", false, "print version")
+	fs.StringVar(&session, "S", "", "restore the session saved by :mksession")
+	fs.StringVar(&info, "i", defaultInfoFile(), "use the info file for the histories (NONE to skip)")
 	if err := fs.Parse(args); err != nil {
 		if err == flag.ErrHelp {
 			return exitCodeOK
@@ -55,7 +61,7 @@ Options:
 		fmt.Printf("%s %s (rev: %s/%s)\n", name, version, revision, runtime.Version())
 		return exitCodeOK
 	}
-	if err := start(fs.Args()); err != nil {
+	if err := start(fs.Args(), session, info); err != nil {
 		if err, ok := err.(interface{ ExitCode() int }); ok {
 			return err.ExitCode()
 		}
@@ -65,8 +71,8 @@ Options:
 	return exitCodeOK
 }
 
-func start(args []string) error {
-	if len(args) > 1 {
+func start(args []string, session, info string) error {
+	if len(args) > 1 || len(args) > 0 && session != "" {
 		return fmt.Errorf("too many files")
 	}
 	editor := editor.NewEditor(
@@ -75,7 +81,17 @@ func start(args []string) error {
 	if err := editor.Init(); err != nil {
 		return err
 	}
-	if len(args) > 0 {
+	if info != "" && info != "NONE" {
+		if err := editor.LoadInfo(info); err != nil {
+			return err
+		}
+		defer editor.SaveInfo(info)
+	}
+	if session != "" {
+		if err := editor.OpenSession(session); err != nil {
+			return err
+		}
+	} else if len(args) > 0 {
 		if err := editor.Open(args[0]); err != nil {
 			return err
 		}
@@ -87,3 +103,11 @@ func start(args []string) error {
 	defer editor.Close()
 	return editor.Run()
 }
+
+func defaultInfoFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bed", "bedinfo")
+}
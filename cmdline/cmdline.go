@@ -6,14 +6,20 @@ import (
 
 	"github.com/itchyny/bed/event"
 	"github.com/itchyny/bed/mathutil"
+	"github.com/itchyny/bed/option"
 )
 
 // Cmdline implements editor.Cmdline
 type Cmdline struct {
 	cmdline   []rune
 	cursor    int
+	killed    []rune
 	completor *completor
 	typ       rune
+	histories map[rune]*history
+	histwin   bool
+	incsearch bool
+	searchCh  chan event.Event
 	eventCh   chan<- event.Event
 	cmdlineCh <-chan event.Event
 	redrawCh  chan<- struct{}
@@ -24,6 +30,8 @@ type Cmdline struct {
 func NewCmdline() *Cmdline {
 	return &Cmdline{
 		completor: newCompletor(&filesystem{}),
+		histories: map[rune]*history{':': {}, '/': {}},
+		searchCh:  make(chan event.Event, 1),
 		mu:        new(sync.Mutex),
 	}
 }
@@ -35,8 +43,15 @@ func (c *Cmdline) Init(eventCh chan<- event.Event, cmdlineCh <-chan event.Event,
 
 // Run the cmdline.
 func (c *Cmdline) Run() {
+	defer close(c.searchCh)
+	go func() {
+		for e := range c.searchCh {
+			c.eventCh <- e
+		}
+	}()
 	for e := range c.cmdlineCh {
 		c.mu.Lock()
+		prev := string(c.cmdline)
 		switch e.Type {
 		case event.StartCmdlineCommand:
 			c.typ = ':'
@@ -47,6 +62,13 @@ func (c *Cmdline) Run() {
 		case event.StartCmdlineSearchBackward:
 			c.typ = '?'
 			c.clear()
+		case event.StartCmdlineHistoryCommand, event.StartCmdlineHistorySearchForward,
+			event.StartCmdlineHistorySearchBackward:
+			c.openHistory(e.Type)
+			c.completor.clear()
+			c.redrawCh <- struct{}{}
+			c.mu.Unlock()
+			continue
 		case event.ExitCmdline:
 			c.clear()
 		case event.CursorLeft:
@@ -63,10 +85,20 @@ func (c *Cmdline) Run() {
 			c.deleteRune()
 		case event.DeleteWordCmdline:
 			c.deleteWord()
+		case event.WordBackwardCmdline:
+			c.wordBackward()
+		case event.WordForwardCmdline:
+			c.wordForward()
 		case event.ClearToHeadCmdline:
 			c.clearToHead()
+		case event.ClearToEndCmdline:
+			c.clearToEnd()
 		case event.ClearCmdline:
 			c.clear()
+		case event.YankCmdline:
+			c.insertString(string(c.killed))
+		case event.InsertRegisterCmdline, event.InsertWordCmdline:
+			c.insertString(e.Arg)
 		case event.Rune:
 			c.insert(e.Rune)
 		case event.CompleteForwardCmdline:
@@ -79,13 +111,32 @@ func (c *Cmdline) Run() {
 			c.redrawCh <- struct{}{}
 			c.mu.Unlock()
 			continue
+		case event.HistoryPrevCmdline, event.HistoryNextCmdline:
+			if e.Type == event.HistoryPrevCmdline {
+				c.historyPrev()
+			} else {
+				c.historyNext()
+			}
+			c.searchChanged(prev)
+			c.completor.clear()
+			c.redrawCh <- struct{}{}
+			c.mu.Unlock()
+			continue
 		case event.ExecuteCmdline:
 			c.execute()
 		default:
 			c.mu.Unlock()
 			continue
 		}
+		switch e.Type {
+		case event.StartCmdlineSearchForward, event.StartCmdlineSearchBackward,
+			event.ExitCmdline, event.ExecuteCmdline:
+		default:
+			c.searchChanged(prev)
+		}
 		c.completor.clear()
+		c.history().reset()
+		c.histwin = false
 		c.mu.Unlock()
 		c.redrawCh <- struct{}{}
 	}
@@ -125,6 +176,32 @@ func (c *Cmdline) deleteRune() {
 }
 
 func (c *Cmdline) deleteWord() {
+	i := c.wordStart()
+	c.kill(c.cmdline[i:c.cursor])
+	c.cmdline = append(c.cmdline[:i], c.cmdline[c.cursor:]...)
+	c.cursor = i
+}
+
+func (c *Cmdline) wordBackward() {
+	c.cursor = c.wordStart()
+}
+
+func (c *Cmdline) wordForward() {
+	i := c.cursor
+	for i < len(c.cmdline) && unicode.IsSpace(c.cmdline[i]) {
+		i++
+	}
+	if i < len(c.cmdline) {
+		isk := isKeyword(c.cmdline[i])
+		for i < len(c.cmdline) && isKeyword(c.cmdline[i]) == isk && !unicode.IsSpace(c.cmdline[i]) {
+			i++
+		}
+	}
+	c.cursor = i
+}
+
+// wordStart returns the start of the word before the cursor.
+func (c *Cmdline) wordStart() int {
 	i := c.cursor
 	for i > 0 && unicode.IsSpace(c.cmdline[i-1]) {
 		i--
@@ -135,8 +212,7 @@ func (c *Cmdline) deleteWord() {
 			i--
 		}
 	}
-	c.cmdline = append(c.cmdline[:i], c.cmdline[c.cursor:]...)
-	c.cursor = i
+	return i
 }
 
 func isKeyword(c rune) bool {
@@ -154,10 +230,16 @@ func (c *Cmdline) clear() {
 }
 
 func (c *Cmdline) clearToHead() {
+	c.kill(c.cmdline[:c.cursor])
 	c.cmdline = c.cmdline[c.cursor:]
 	c.cursor = 0
 }
 
+func (c *Cmdline) clearToEnd() {
+	c.kill(c.cmdline[c.cursor:])
+	c.cmdline = c.cmdline[:c.cursor]
+}
+
 func (c *Cmdline) insert(ch rune) {
 	if unicode.IsPrint(ch) {
 		c.cmdline = append(c.cmdline, '\x00')
@@ -167,6 +249,19 @@ func (c *Cmdline) insert(ch rune) {
 	}
 }
 
+// kill saves the deleted runes to be yanked later.
+func (c *Cmdline) kill(rs []rune) {
+	if len(rs) > 0 {
+		c.killed = append([]rune(nil), rs...)
+	}
+}
+
+func (c *Cmdline) insertString(s string) {
+	for _, ch := range s {
+		c.insert(ch)
+	}
+}
+
 func (c *Cmdline) complete(forward bool) {
 	cmd, _, prefix, _, arg, err := parse(c.cmdline)
 	if err != nil {
@@ -178,7 +273,76 @@ func (c *Cmdline) complete(forward bool) {
 	c.cursor = len(c.cmdline)
 }
 
+func (c *Cmdline) history() *history {
+	if c.typ == ':' {
+		return c.histories[':']
+	}
+	return c.histories['/']
+}
+
+func (c *Cmdline) historyPrev() {
+	h := c.history()
+	if c.histwin {
+		if h.index > 0 {
+			h.index--
+			c.start(h.entries[h.index])
+		}
+		return
+	}
+	if cmdline, ok := h.prev(string(c.cmdline)); ok {
+		c.start(cmdline)
+	}
+}
+
+func (c *Cmdline) historyNext() {
+	h := c.history()
+	if c.histwin {
+		if h.index < len(h.entries)-1 {
+			h.index++
+			c.start(h.entries[h.index])
+		}
+		return
+	}
+	if cmdline, ok := h.next(); ok {
+		c.start(cmdline)
+	}
+}
+
+// openHistory opens the history window with the last entry selected.
+func (c *Cmdline) openHistory(typ event.Type) {
+	switch typ {
+	case event.StartCmdlineHistoryCommand:
+		c.typ = ':'
+	case event.StartCmdlineHistorySearchForward:
+		c.typ = '/'
+	default:
+		c.typ = '?'
+	}
+	h := c.history()
+	h.reset()
+	c.clear()
+	if len(h.entries) > 0 {
+		c.histwin = true
+		h.index--
+		c.start(h.entries[h.index])
+	}
+}
+
+// searchChanged emits the event for the incremental search when the search
+// pattern is changed. The event is sent from another goroutine not to block
+// the cmdline, and the pending event of the outdated pattern is dropped.
+func (c *Cmdline) searchChanged(prev string) {
+	if c.incsearch && (c.typ == '/' || c.typ == '?') && string(c.cmdline) != prev {
+		select {
+		case <-c.searchCh:
+		default:
+		}
+		c.searchCh <- event.Event{Type: event.IncrementalSearch, Arg: string(c.cmdline), Rune: c.typ}
+	}
+}
+
 func (c *Cmdline) execute() {
+	c.history().add(string(c.cmdline))
 	switch c.typ {
 	case ':':
 		cmd, r, _, bang, arg, err := parse(c.cmdline)
@@ -198,9 +362,35 @@ func (c *Cmdline) execute() {
 	}
 }
 
+// SetOptions sets the options.
+func (c *Cmdline) SetOptions(options option.Options) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.incsearch = options.IncSearch
+}
+
+// SetBufferNames sets the buffer names for completion.
+func (c *Cmdline) SetBufferNames(names []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.completor.buffers = names
+}
+
 // Get returns the current state of cmdline.
 func (c *Cmdline) Get() ([]rune, int, []string, int) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	return c.cmdline, c.cursor, c.completor.results, c.completor.index
 }
+
+// GetHistory returns the entries of the history window and the selected
+// index, or nil if the history window is not opened.
+func (c *Cmdline) GetHistory() ([]string, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.histwin {
+		return nil, 0
+	}
+	h := c.history()
+	return append([]string(nil), h.entries...), h.index
+}
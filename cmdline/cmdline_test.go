@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/option"
 )
 
 func TestNewCmdline(t *testing.T) {
@@ -270,6 +271,59 @@ func TestCmdlineCursorClearToHead(t *testing.T) {
 	}
 }
 
+func TestCmdlineCursorWordMotion(t *testing.T) {
+	c := NewCmdline()
+	c.insertString("set ob=dec  0x1f")
+
+	for _, testCase := range []struct {
+		forward bool
+		cursor  int
+	}{
+		{false, 12}, {false, 7}, {false, 6}, {false, 4}, {false, 0}, {false, 0},
+		{true, 3}, {true, 6}, {true, 7}, {true, 10}, {true, 16}, {true, 16},
+	} {
+		if testCase.forward {
+			c.wordForward()
+		} else {
+			c.wordBackward()
+		}
+		if _, cursor, _, _ := c.Get(); cursor != testCase.cursor {
+			t.Errorf("cursor should be %d but got %d", testCase.cursor, cursor)
+		}
+	}
+}
+
+func TestCmdlineCursorClearToEndYank(t *testing.T) {
+	c := NewCmdline()
+	c.insertString("abcde")
+	c.cursorLeft()
+	c.cursorLeft()
+	c.clearToEnd()
+
+	cmdline, cursor, _, _ := c.Get()
+	if string(cmdline) != "abc" {
+		t.Errorf("cmdline should be %v but got %v", "abc", string(cmdline))
+	}
+	if cursor != 3 {
+		t.Errorf("cursor should be 3 but got %v", cursor)
+	}
+
+	c.clearToEnd()
+	c.cursorHead()
+	c.insertString(string(c.killed))
+	c.deleteWord()
+	c.cursorEnd()
+	c.insertString(string(c.killed))
+
+	cmdline, cursor, _, _ = c.Get()
+	if string(cmdline) != "abcde" {
+		t.Errorf("cmdline should be %v but got %v", "abcde", string(cmdline))
+	}
+	if cursor != 5 {
+		t.Errorf("cursor should be 5 but got %v", cursor)
+	}
+}
+
 func TestCmdlineCursorInsert(t *testing.T) {
 	c := NewCmdline()
 
@@ -634,3 +688,109 @@ func TestCmdlineSearch(t *testing.T) {
 		t.Errorf("cmdline should emit search event with Rune %q but got %q", '?', e.Rune)
 	}
 }
+
+func TestCmdlineIncrementalSearch(t *testing.T) {
+	c := NewCmdline()
+	eventCh, cmdlineCh, redrawCh := make(chan event.Event, 10), make(chan event.Event), make(chan struct{})
+	c.Init(eventCh, cmdlineCh, redrawCh)
+	c.SetOptions(option.Options{IncSearch: true})
+	go c.Run()
+	for _, e := range []event.Event{
+		event.Event{Type: event.StartCmdlineSearchBackward},
+		event.Event{Type: event.Rune, Rune: 'a'}, event.Event{Type: event.CursorLeft},
+		event.Event{Type: event.Rune, Rune: 'x'},
+	} {
+		cmdlineCh <- e
+		<-redrawCh
+	}
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case e := <-eventCh:
+			if e.Type != event.IncrementalSearch || e.Rune != '?' {
+				t.Fatalf("cmdline should emit IncrementalSearch event but got %v", e)
+			}
+			if e.Arg == "xa" {
+				close(cmdlineCh)
+				return
+			}
+		case <-timeout:
+			t.Fatalf("cmdline should emit IncrementalSearch event with Arg %q", "xa")
+		}
+	}
+}
+
+func TestCmdlineExecuteGotoExpression(t *testing.T) {
+	c := NewCmdline()
+	ch := make(chan event.Event, 1)
+	c.Init(ch, make(chan event.Event), make(chan struct{}))
+	for _, cmd := range []struct {
+		cmd  string
+		name string
+		pos  event.Position
+		err  string
+	}{
+		{"goto", "go[to]", event.Absolute{}, ""},
+		{"go 0x40 + 4 * 4", "go[to]", event.Absolute{Offset: 0x50}, ""},
+		{"goto $-0x10", "go[to]", event.End{Offset: -0x10}, ""},
+		{"$/2", "goto", nil, ""},
+		{"10goto", "go[to]", event.Absolute{Offset: 10}, ""},
+		{"goto 0x10 foo", "", nil, "invalid argument for goto: 0x10 foo"},
+		{"10goto 20", "", nil, "range not allowed with an argument for goto: 20"},
+	} {
+		c.clear()
+		c.cmdline = []rune(cmd.cmd)
+		c.typ = ':'
+		c.execute()
+		e := <-ch
+		if cmd.err != "" {
+			if e.Type != event.Error || e.Error.Error() != cmd.err {
+				t.Errorf("cmdline should emit error %q with %q but got %+v", cmd.err, cmd.cmd, e)
+			}
+			continue
+		}
+		if e.CmdName != cmd.name {
+			t.Errorf("cmdline should report command name %q but got %q", cmd.name, e.CmdName)
+		}
+		if e.Type != event.CursorGoto {
+			t.Errorf("cmdline should emit CursorGoto event with %q but got %+v", cmd.cmd, e)
+		}
+		if _, ok := e.Range.From.(event.Expression); ok && cmd.pos == nil {
+			continue
+		}
+		if !reflect.DeepEqual(e.Range.From, cmd.pos) {
+			t.Errorf("cmdline should report command with position %#v but got %#v", cmd.pos, e.Range.From)
+		}
+	}
+}
+
+func TestCmdlineExecuteFilter(t *testing.T) {
+	c := NewCmdline()
+	ch := make(chan event.Event, 1)
+	c.Init(ch, make(chan event.Event), make(chan struct{}))
+	for _, cmd := range []struct {
+		cmd string
+		arg string
+		rng *event.Range
+	}{
+		{"!ls -l", "ls -l", nil},
+		{"! date", "date", nil},
+		{"'<,'>!base64", "base64", &event.Range{From: event.VisualStart{}, To: event.VisualEnd{}}},
+		{"0,$!xxd -r", "xxd -r", &event.Range{From: event.Absolute{}, To: event.End{}}},
+	} {
+		c.clear()
+		c.cmdline = []rune(cmd.cmd)
+		c.typ = ':'
+		c.execute()
+		e := <-ch
+		if e.Type != event.Filter {
+			t.Errorf("cmdline should emit Filter event with %q but got %+v", cmd.cmd, e)
+		}
+		if e.Arg != cmd.arg {
+			t.Errorf("cmdline should report command with arg %q but got %q", cmd.arg, e.Arg)
+		}
+		if !reflect.DeepEqual(e.Range, cmd.rng) {
+			t.Errorf("cmdline should report command with range %#v but got %#v", cmd.rng, e.Range)
+		}
+	}
+}
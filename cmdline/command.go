@@ -12,7 +12,46 @@ var commands = []command{
 	{"ene[w]", event.Enew},
 	{"new", event.New},
 	{"vne[w]", event.Vnew},
+	{"ls", event.Buffers},
+	{"buffers", event.Buffers},
+	{"files", event.Buffers},
+	{"b[uffer]", event.Buffer},
+	{"bn[ext]", event.BufferNext},
+	{"bp[revious]", event.BufferPrevious},
+	{"bN[ext]", event.BufferPrevious},
+	{"bd[elete]", event.BufferDelete},
+	{"mks[ession]", event.MakeSession},
+	{"hi[ghlight]", event.Highlight},
+	{"colo[rscheme]", event.Colorscheme},
+	{"se[t]", event.Set},
 	{"winc[md]", event.Wincmd},
+	{"on[ly]", event.OnlyWindow},
+	{"res[ize]", event.Resize},
+	{"vert[ical]", event.VerticalResize},
+	{"tabe[dit]", event.TabNew},
+	{"tabnew", event.TabNew},
+	{"tabc[lose]", event.TabClose},
+	{"tabn[ext]", event.TabNext},
+	{"tabp[revious]", event.TabPrevious},
+	{"tabN[ext]", event.TabPrevious},
+	{"tabm[ove]", event.TabMove},
+	{"go[to]", event.CursorGoto},
+
+	{"fil[l]", event.Fill},
+	{"r[ead]", event.Read},
+	{"di[splay]", event.Display},
+	{"hash", event.Hash},
+	{"checksum-fix", event.ChecksumFix},
+
+	{"searcha[ll]", event.SearchAll},
+	{"finda[ll]", event.FindAll},
+	{"str[ings]", event.Strings},
+	{"cope[n]", event.QuickfixOpen},
+	{"ccl[ose]", event.QuickfixClose},
+	{"cn[ext]", event.QuickfixNext},
+	{"cp[revious]", event.QuickfixPrevious},
+	{"cN[ext]", event.QuickfixPrevious},
+	{"cc", event.QuickfixGoto},
 
 	{"u[ndo]", event.Undo},
 	{"red[o]", event.Redo},
@@ -6,11 +6,16 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/itchyny/bed/display"
 	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/highlight"
+	"github.com/itchyny/bed/option"
+	"github.com/itchyny/bed/pathutil"
 )
 
 type completor struct {
 	fs      fs
+	buffers []string
 	target  string
 	arg     string
 	results []string
@@ -30,10 +35,20 @@ func (c *completor) clear() {
 
 func (c *completor) complete(cmdline string, cmd command, prefix string, arg string, forward bool) string {
 	switch cmd.eventType {
-	case event.Edit, event.New, event.Vnew, event.Write:
+	case event.Edit, event.New, event.Vnew, event.TabNew, event.Write, event.MakeSession:
 		return c.completeFilepaths(cmdline, prefix, arg, forward)
 	case event.Wincmd:
 		return c.completeWincmd(cmdline, prefix, arg, forward)
+	case event.Buffer, event.BufferDelete:
+		return c.completeBuffers(cmdline, prefix, arg, forward)
+	case event.Highlight:
+		return c.completeHighlight(cmdline, prefix, arg, forward)
+	case event.Colorscheme:
+		return c.completeColorscheme(cmdline, prefix, arg, forward)
+	case event.Set:
+		return c.completeOption(cmdline, prefix, arg, forward)
+	case event.Display:
+		return c.completeNames(cmdline, prefix, arg, forward, display.Names(), strings.HasPrefix)
 	default:
 		c.results = nil
 		c.index = 0
@@ -112,7 +127,7 @@ func (c *completor) listFileNames(arg string) (string, []string) {
 			targets = append(targets, name)
 		}
 	} else {
-		path, err := homedirExpand(arg)
+		path, err := pathutil.HomedirExpand(arg)
 		if err != nil {
 			return arg, nil
 		}
@@ -216,18 +231,94 @@ func (c *completor) completeWincmd(cmdline string, prefix string, arg string, fo
 		return cmdline
 	}
 	c.target = cmdline
-	c.results = []string{"n", "h", "l", "k", "j", "H", "L", "K", "J", "t", "b", "p"}
+	c.results = []string{"n", "h", "l", "k", "j", "H", "L", "K", "J", "t", "b", "p", "r", "R", "x", "o"}
 	c.index = -1
 	return cmdline
 }
 
-func homedirExpand(path string) (string, error) {
-	if !strings.HasPrefix(path, "~") {
-		return path, nil
+func (c *completor) completeBuffers(cmdline string, prefix string, arg string, forward bool) string {
+	return c.completeNames(cmdline, prefix, arg, forward, c.buffers, strings.Contains)
+}
+
+func (c *completor) completeHighlight(cmdline string, prefix string, arg string, forward bool) string {
+	if strings.ContainsRune(strings.TrimSpace(arg), ' ') {
+		return cmdline
+	}
+	return c.completeNames(cmdline, prefix, arg, forward,
+		append([]string{"clear"}, highlight.Groups...), hasPrefixFold)
+}
+
+func (c *completor) completeColorscheme(cmdline string, prefix string, arg string, forward bool) string {
+	names := highlight.ColorSchemes()
+	if dir, err := os.UserConfigDir(); err == nil {
+		if f, err := c.fs.Open(filepath.Join(dir, "bed", "colors")); err == nil {
+			fileInfos, _ := f.Readdir(1000)
+			f.Close()
+			for _, fileInfo := range fileInfos {
+				if !fileInfo.IsDir() {
+					names = append(names, fileInfo.Name())
+				}
+			}
+		}
+	}
+	return c.completeNames(cmdline, prefix, arg, forward, names, strings.HasPrefix)
+}
+
+func (c *completor) completeOption(cmdline string, prefix string, arg string, forward bool) string {
+	if i := strings.LastIndexByte(arg, ' '); i >= 0 {
+		prefix, arg = strings.TrimSuffix(prefix, " ")+" "+arg[:i], arg[i+1:]
+	}
+	if i := strings.IndexByte(arg, '='); i >= 0 {
+		var names []string
+		for _, value := range option.Values(arg[:i]) {
+			names = append(names, arg[:i+1]+value)
+		}
+		return c.completeNames(cmdline, prefix, arg, forward, names, strings.HasPrefix)
+	}
+	names := option.Names()
+	for _, p := range []string{"no", "inv"} {
+		if strings.HasPrefix(arg, p) {
+			for i, name := range names {
+				names[i] = p + name
+			}
+			break
+		}
+	}
+	return c.completeNames(cmdline, prefix, arg, forward, names, strings.HasPrefix)
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+func (c *completor) completeNames(cmdline string, prefix string, arg string, forward bool,
+	names []string, match func(string, string) bool) string {
+	if !strings.HasSuffix(prefix, " ") {
+		prefix += " "
 	}
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+	if len(c.results) > 0 {
+		return c.completeNext(prefix, forward)
 	}
-	return filepath.Join(home, path[1:]), nil
+	c.target = cmdline
+	c.arg = ""
+	c.results = nil
+	for _, name := range names {
+		if match(name, arg) {
+			c.results = append(c.results, name)
+		}
+	}
+	if len(c.results) == 1 {
+		cmdline := prefix + c.results[0]
+		c.results = nil
+		return cmdline
+	}
+	if len(c.results) > 1 {
+		if forward {
+			c.index = 0
+			return prefix + c.results[0]
+		}
+		c.index = len(c.results) - 1
+		return prefix + c.results[len(c.results)-1]
+	}
+	return cmdline
 }
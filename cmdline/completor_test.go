@@ -297,3 +297,107 @@ func TestCompletorCompleteWincmd(t *testing.T) {
 		t.Errorf("completion index should be %d but got %d", 0, c.index)
 	}
 }
+
+func TestCompletorCompleteBuffers(t *testing.T) {
+	c := newCompletor(&mockFilesystem{})
+	c.buffers = []string{"foo.bin", "bar.bin", "/tmp/foo.txt"}
+	cmdline := "b foo"
+	cmd, _, prefix, _, arg, _ := parse([]rune(cmdline))
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "b foo.bin" {
+		t.Errorf("cmdline should be %q but got %q", "b foo.bin", cmdline)
+	}
+	if c.index != 0 {
+		t.Errorf("completion index should be %d but got %d", 0, c.index)
+	}
+
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "b /tmp/foo.txt" {
+		t.Errorf("cmdline should be %q but got %q", "b /tmp/foo.txt", cmdline)
+	}
+
+	c.clear()
+	cmdline = "bd ar"
+	cmd, _, prefix, _, arg, _ = parse([]rune(cmdline))
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "bd bar.bin" {
+		t.Errorf("cmdline should be %q but got %q", "bd bar.bin", cmdline)
+	}
+	if c.results != nil {
+		t.Errorf("completion results should be nil but got %v", c.results)
+	}
+}
+
+func TestCompletorCompleteHighlight(t *testing.T) {
+	c := newCompletor(&mockFilesystem{})
+	cmdline := "hi ascii"
+	cmd, _, prefix, _, arg, _ := parse([]rune(cmdline))
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "hi AsciiPrintable" {
+		t.Errorf("cmdline should be %q but got %q", "hi AsciiPrintable", cmdline)
+	}
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "hi AsciiWhitespace" {
+		t.Errorf("cmdline should be %q but got %q", "hi AsciiWhitespace", cmdline)
+	}
+
+	c.clear()
+	cmdline = "hi Offset fg"
+	cmd, _, prefix, _, arg, _ = parse([]rune(cmdline))
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "hi Offset fg" {
+		t.Errorf("cmdline should be %q but got %q", "hi Offset fg", cmdline)
+	}
+}
+
+func TestCompletorCompleteOption(t *testing.T) {
+	c := newCompletor(&mockFilesystem{})
+	cmdline := "set c"
+	cmd, _, prefix, _, arg, _ := parse([]rune(cmdline))
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "set checksumfix" {
+		t.Errorf("cmdline should be %q but got %q", "set checksumfix", cmdline)
+	}
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "set colorbytes" {
+		t.Errorf("cmdline should be %q but got %q", "set colorbytes", cmdline)
+	}
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "set crosshair" {
+		t.Errorf("cmdline should be %q but got %q", "set crosshair", cmdline)
+	}
+
+	c.clear()
+	cmdline = "set colorbytes nocr"
+	cmd, _, prefix, _, arg, _ = parse([]rune(cmdline))
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "set colorbytes nocrosshair" {
+		t.Errorf("cmdline should be %q but got %q", "set colorbytes nocrosshair", cmdline)
+	}
+
+	c.clear()
+	cmdline = "set enc=utf-16"
+	cmd, _, prefix, _, arg, _ = parse([]rune(cmdline))
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "set enc=utf-16le" {
+		t.Errorf("cmdline should be %q but got %q", "set enc=utf-16le", cmdline)
+	}
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "set enc=utf-16be" {
+		t.Errorf("cmdline should be %q but got %q", "set enc=utf-16be", cmdline)
+	}
+}
+
+func TestCompletorCompleteDisplay(t *testing.T) {
+	c := newCompletor(&mockFilesystem{})
+	cmdline := "display word32"
+	cmd, _, prefix, _, arg, _ := parse([]rune(cmdline))
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "display word32le" {
+		t.Errorf("cmdline should be %q but got %q", "display word32le", cmdline)
+	}
+	cmdline = c.complete(cmdline, cmd, prefix, arg, true)
+	if cmdline != "display word32be" {
+		t.Errorf("cmdline should be %q but got %q", "display word32be", cmdline)
+	}
+}
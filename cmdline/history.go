@@ -0,0 +1,106 @@
+package cmdline
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const historySize = 100
+
+type history struct {
+	entries []string
+	index   int
+	prefix  string
+}
+
+func (h *history) add(entry string) {
+	if entry == "" {
+		return
+	}
+	for i, e := range h.entries {
+		if e == entry {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > historySize {
+		h.entries = h.entries[len(h.entries)-historySize:]
+	}
+	h.reset()
+}
+
+func (h *history) reset() {
+	h.index = len(h.entries)
+	h.prefix = ""
+}
+
+// prev recalls the previous entry starting with the cmdline typed before
+// the history navigation.
+func (h *history) prev(cmdline string) (string, bool) {
+	if h.index >= len(h.entries) {
+		h.index, h.prefix = len(h.entries), cmdline
+	}
+	for i := h.index - 1; i >= 0; i-- {
+		if strings.HasPrefix(h.entries[i], h.prefix) {
+			h.index = i
+			return h.entries[i], true
+		}
+	}
+	return "", false
+}
+
+// next recalls the next entry, and the typed cmdline after the last entry.
+func (h *history) next() (string, bool) {
+	if h.index >= len(h.entries) {
+		return "", false
+	}
+	for i := h.index + 1; i < len(h.entries); i++ {
+		if strings.HasPrefix(h.entries[i], h.prefix) {
+			h.index = i
+			return h.entries[i], true
+		}
+	}
+	prefix := h.prefix
+	h.reset()
+	return prefix, true
+}
+
+// ReadHistory reads the histories from the info file. Each line is an
+// entry of the command line history prefixed by : or the search history
+// prefixed by /. Empty lines and lines starting with # are ignored.
+func (c *Cmdline) ReadHistory(r io.Reader) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if h, ok := c.histories[rune(line[0])]; ok {
+			h.add(line[1:])
+		}
+	}
+	return s.Err()
+}
+
+// WriteHistory writes the histories in the format of the info file.
+func (c *Cmdline) WriteHistory(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# This file is written by bed. Edit it with care.")
+	for _, x := range []struct {
+		typ   rune
+		title string
+	}{{':', "Command line history"}, {'/', "Search history"}} {
+		fmt.Fprintf(bw, "\n# %s\n", x.title)
+		for _, entry := range c.histories[x.typ].entries {
+			fmt.Fprintf(bw, "%c%s\n", x.typ, entry)
+		}
+	}
+	return bw.Flush()
+}
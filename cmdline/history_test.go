@@ -0,0 +1,136 @@
+package cmdline
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/bed/event"
+)
+
+func TestHistoryAdd(t *testing.T) {
+	h := &history{}
+	for _, entry := range []string{"set", "", "write", "set", "edit"} {
+		h.add(entry)
+	}
+	if expected := []string{"write", "set", "edit"}; !reflect.DeepEqual(h.entries, expected) {
+		t.Errorf("entries should be %v but got %v", expected, h.entries)
+	}
+	for i := 0; i < historySize+10; i++ {
+		h.add(strings.Repeat("x", i+1))
+	}
+	if len(h.entries) != historySize {
+		t.Errorf("entries should be limited to %d but got %d", historySize, len(h.entries))
+	}
+	if h.index != historySize {
+		t.Errorf("index should be %d but got %d", historySize, h.index)
+	}
+}
+
+func TestHistoryPrevNext(t *testing.T) {
+	h := &history{}
+	for _, entry := range []string{"set ob=dec", "write", "set enc=latin1", "wq"} {
+		h.add(entry)
+	}
+	for _, testCase := range []struct {
+		prev     bool
+		expected string
+		ok       bool
+	}{
+		{true, "set enc=latin1", true},
+		{true, "set ob=dec", true},
+		{true, "", false},
+		{false, "set enc=latin1", true},
+		{false, "se", true},
+		{false, "", false},
+		{true, "set enc=latin1", true},
+	} {
+		var got string
+		var ok bool
+		if testCase.prev {
+			got, ok = h.prev("se")
+		} else {
+			got, ok = h.next()
+		}
+		if got != testCase.expected || ok != testCase.ok {
+			t.Errorf("history should recall %q, %v but got %q, %v", testCase.expected, testCase.ok, got, ok)
+		}
+	}
+}
+
+func TestCmdlineHistory(t *testing.T) {
+	c := NewCmdline()
+	c.eventCh = make(chan event.Event, 10)
+	c.typ = ':'
+	for _, cmdline := range []string{"set ob=dec", "write", "set enc=latin1"} {
+		c.start(cmdline)
+		c.execute()
+	}
+	c.start("set")
+	c.historyPrev()
+	if cmdline, cursor, _, _ := c.Get(); string(cmdline) != "set enc=latin1" || cursor != 14 {
+		t.Errorf("cmdline should be %q but got %q", "set enc=latin1", string(cmdline))
+	}
+	c.historyPrev()
+	c.historyPrev()
+	if cmdline, _, _, _ := c.Get(); string(cmdline) != "set ob=dec" {
+		t.Errorf("cmdline should be %q but got %q", "set ob=dec", string(cmdline))
+	}
+	c.historyNext()
+	c.historyNext()
+	if cmdline, _, _, _ := c.Get(); string(cmdline) != "set" {
+		t.Errorf("cmdline should be %q but got %q", "set", string(cmdline))
+	}
+	if entries, _ := c.GetHistory(); entries != nil {
+		t.Errorf("history window should not be opened but got %v", entries)
+	}
+
+	c.openHistory(event.StartCmdlineHistoryCommand)
+	c.historyPrev()
+	entries, index := c.GetHistory()
+	if expected := []string{"set ob=dec", "write", "set enc=latin1"}; !reflect.DeepEqual(entries, expected) {
+		t.Errorf("history entries should be %v but got %v", expected, entries)
+	}
+	if index != 1 {
+		t.Errorf("history index should be 1 but got %d", index)
+	}
+	if cmdline, _, _, _ := c.Get(); string(cmdline) != "write" {
+		t.Errorf("cmdline should be %q but got %q", "write", string(cmdline))
+	}
+
+	c.openHistory(event.StartCmdlineHistorySearchForward)
+	if entries, _ := c.GetHistory(); entries != nil {
+		t.Errorf("history window should not be opened for empty history but got %v", entries)
+	}
+}
+
+func TestCmdlineReadWriteHistory(t *testing.T) {
+	c := NewCmdline()
+	input := `# comment
+:set ob=dec
+/abc
+
+:write
+?def
+`
+	if err := c.ReadHistory(strings.NewReader(input)); err != nil {
+		t.Fatalf("ReadHistory should not return error but got %v", err)
+	}
+	var b bytes.Buffer
+	if err := c.WriteHistory(&b); err != nil {
+		t.Fatalf("WriteHistory should not return error but got %v", err)
+	}
+	expected := `# This file is written by bed. Edit it with care.
+
+# Command line history
+:set ob=dec
+:write
+
+# Search history
+/abc
+`
+	if got := b.String(); got != expected {
+		t.Errorf("history should be %q but got %q", expected, got)
+	}
+}
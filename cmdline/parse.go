@@ -17,6 +17,10 @@ func parse(cmdline []rune) (command, *event.Range, string, bool, string, error)
 		return command{}, nil, "", false, "", nil
 	}
 	r, i := event.ParseRange(cmdline, i)
+	if i < l && cmdline[i] == '!' {
+		return command{"!", event.Filter}, r, string(cmdline[:i+1]), false,
+			strings.TrimSpace(string(cmdline[i+1:])), nil
+	}
 	j := i
 	for j < l && !unicode.IsSpace(cmdline[j]) {
 		j++
@@ -34,7 +38,15 @@ func parse(cmdline []rune) (command, *event.Range, string, bool, string, error)
 		}
 		for _, c := range expand(cmd.name) {
 			if cmdName == c {
-				return cmd, r, string(cmdline[:k]), bang, strings.TrimSpace(string(cmdline[k:])), nil
+				arg := strings.TrimSpace(string(cmdline[k:]))
+				if cmd.eventType == event.CursorGoto {
+					r, err := parseGotoArg(r, arg)
+					if err != nil {
+						return command{}, nil, "", false, "", err
+					}
+					return cmd, r, string(cmdline[:k]), bang, "", nil
+				}
+				return cmd, r, string(cmdline[:k]), bang, arg, nil
 			}
 		}
 	}
@@ -60,3 +72,21 @@ func expand(name string) []string {
 	}
 	return cmds
 }
+
+func parseGotoArg(r *event.Range, arg string) (*event.Range, error) {
+	if arg == "" {
+		if r == nil {
+			return &event.Range{From: event.Absolute{}}, nil
+		}
+		return r, nil
+	}
+	if r != nil {
+		return nil, fmt.Errorf("range not allowed with an argument for goto: %s", arg)
+	}
+	xs := []rune(arg)
+	pos, i := event.ParsePos(xs, 0)
+	if pos == nil || i != len(xs) {
+		return nil, fmt.Errorf("invalid argument for goto: %s", arg)
+	}
+	return &event.Range{From: pos}, nil
+}
@@ -0,0 +1,112 @@
+package display
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Display is the format of the bytes in the hex column; the base of the
+// digits and the size of the words in bytes. The bytes of the words are
+// shown in the order of the endianness.
+type Display struct {
+	Name      string
+	Base      int
+	Size      int
+	BigEndian bool
+}
+
+// Displays is the list of the displays.
+var Displays = []Display{
+	{"hex", 16, 1, false},
+	{"binary", 2, 1, false},
+	{"octal", 8, 1, false},
+	{"decimal", 10, 1, false},
+	{"word16le", 16, 2, false},
+	{"word16be", 16, 2, true},
+	{"word32le", 16, 4, false},
+	{"word32be", 16, 4, true},
+	{"word64le", 16, 8, false},
+	{"word64be", 16, 8, true},
+}
+
+// Default returns the default display.
+func Default() Display {
+	return Displays[0]
+}
+
+// Lookup the display by the name.
+func Lookup(name string) (Display, error) {
+	for _, d := range Displays {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return Display{}, fmt.Errorf("unknown display: %s", name)
+}
+
+// Names returns the names of the displays.
+func Names() []string {
+	names := make([]string, len(Displays))
+	for i, d := range Displays {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// Digits returns the number of the digits of a byte.
+func (d Display) Digits() int {
+	switch d.Base {
+	case 2:
+		return 8
+	case 8, 10:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// Width returns the width of the bytes. The words are separated by spaces.
+func (d Display) Width(n int) int {
+	return n / d.Size * (d.Digits()*d.Size + 1)
+}
+
+// Position returns the position of the byte in the line.
+func (d Display) Position(i int) int {
+	j := i % d.Size
+	if !d.BigEndian {
+		j = d.Size - 1 - j
+	}
+	return i/d.Size*(d.Digits()*d.Size+1) + 1 + j*d.Digits()
+}
+
+// Format the byte in the digits.
+func (d Display) Format(b byte) string {
+	switch d.Base {
+	case 2:
+		return fmt.Sprintf("%08b", b)
+	case 8:
+		return fmt.Sprintf("%03o", b)
+	case 10:
+		return fmt.Sprintf("%3d", b)
+	default:
+		return fmt.Sprintf("%02x", b)
+	}
+}
+
+// Digit returns the value of the digit character.
+func (d Display) Digit(ch rune) (byte, bool) {
+	n, err := strconv.ParseUint(string(ch), d.Base, 8)
+	if err != nil || 'A' <= ch && ch <= 'Z' {
+		return 0, false
+	}
+	return byte(n), true
+}
+
+// Place returns the place value of the digit of the index in a byte.
+func (d Display) Place(index int) int {
+	place := 1
+	for i := index + 1; i < d.Digits(); i++ {
+		place *= d.Base
+	}
+	return place
+}
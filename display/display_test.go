@@ -0,0 +1,74 @@
+package display
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDisplay(t *testing.T) {
+	for _, testCase := range []struct {
+		name      string
+		digits    int
+		width     int
+		positions []int
+		formatted string
+	}{
+		{"hex", 2, 24, []int{1, 4, 7, 10, 13, 16, 19, 22}, "0a"},
+		{"binary", 8, 72, []int{1, 10, 19, 28, 37, 46, 55, 64}, "00001010"},
+		{"octal", 3, 32, []int{1, 5, 9, 13, 17, 21, 25, 29}, "012"},
+		{"decimal", 3, 32, []int{1, 5, 9, 13, 17, 21, 25, 29}, " 10"},
+		{"word16le", 2, 20, []int{3, 1, 8, 6, 13, 11, 18, 16}, "0a"},
+		{"word16be", 2, 20, []int{1, 3, 6, 8, 11, 13, 16, 18}, "0a"},
+		{"word32le", 2, 18, []int{7, 5, 3, 1, 16, 14, 12, 10}, "0a"},
+		{"word64be", 2, 17, []int{1, 3, 5, 7, 9, 11, 13, 15}, "0a"},
+	} {
+		d, err := Lookup(testCase.name)
+		if err != nil {
+			t.Fatalf("Lookup(%q) should not return error but got %v", testCase.name, err)
+		}
+		if got := d.Digits(); got != testCase.digits {
+			t.Errorf("%s.Digits() should be %d but got %d", testCase.name, testCase.digits, got)
+		}
+		if got := d.Width(8); got != testCase.width {
+			t.Errorf("%s.Width(8) should be %d but got %d", testCase.name, testCase.width, got)
+		}
+		var positions []int
+		for i := 0; i < 8; i++ {
+			positions = append(positions, d.Position(i))
+		}
+		if !reflect.DeepEqual(positions, testCase.positions) {
+			t.Errorf("%s.Position should be %v but got %v", testCase.name, testCase.positions, positions)
+		}
+		if got := d.Format(0x0a); got != testCase.formatted {
+			t.Errorf("%s.Format(0x0a) should be %q but got %q", testCase.name, testCase.formatted, got)
+		}
+	}
+	if _, err := Lookup("unknown"); err == nil || err.Error() != "unknown display: unknown" {
+		t.Errorf("Lookup should return error but got %v", err)
+	}
+}
+
+func TestDisplayDigit(t *testing.T) {
+	for _, testCase := range []struct {
+		name  string
+		ch    rune
+		digit byte
+		ok    bool
+	}{
+		{"hex", 'f', 0x0f, true},
+		{"hex", 'F', 0, false},
+		{"hex", 'g', 0, false},
+		{"binary", '1', 1, true},
+		{"binary", '2', 0, false},
+		{"octal", '7', 7, true},
+		{"octal", '8', 0, false},
+		{"decimal", '9', 9, true},
+		{"decimal", 'a', 0, false},
+	} {
+		d, _ := Lookup(testCase.name)
+		if digit, ok := d.Digit(testCase.ch); digit != testCase.digit || ok != testCase.ok {
+			t.Errorf("%s.Digit(%q) should be %d, %v but got %d, %v",
+				testCase.name, testCase.ch, testCase.digit, testCase.ok, digit, ok)
+		}
+	}
+}
@@ -1,10 +1,20 @@
 package editor
 
-import "github.com/itchyny/bed/event"
+import (
+	"io"
+
+	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/option"
+)
 
 // Cmdline defines the required cmdline interface for the editor.
 type Cmdline interface {
 	Init(chan<- event.Event, <-chan event.Event, chan<- struct{})
 	Run()
 	Get() ([]rune, int, []string, int)
+	GetHistory() ([]string, int)
+	SetOptions(option.Options)
+	SetBufferNames([]string)
+	ReadHistory(io.Reader) error
+	WriteHistory(io.Writer) error
 }
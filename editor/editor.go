@@ -3,46 +3,58 @@ package editor
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/itchyny/bed/buffer"
 	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/highlight"
+	"github.com/itchyny/bed/mathutil"
 	"github.com/itchyny/bed/mode"
+	"github.com/itchyny/bed/option"
 	"github.com/itchyny/bed/state"
 )
 
 // Editor is the main struct for this command.
 type Editor struct {
-	ui            UI
-	wm            Manager
-	cmdline       Cmdline
-	mode          mode.Mode
-	prevMode      mode.Mode
-	searchTarget  string
-	searchMode    rune
-	prevEventType event.Type
-	buffer        *buffer.Buffer
-	err           error
-	errtyp        int
-	cmdEventCh    chan event.Event
-	wmEventCh     chan event.Event
-	uiEventCh     chan event.Event
-	redrawCh      chan struct{}
-	cmdlineCh     chan event.Event
-	quitCh        chan struct{}
-	mu            *sync.Mutex
+	ui              UI
+	wm              Manager
+	cmdline         Cmdline
+	mode            mode.Mode
+	prevMode        mode.Mode
+	searchTarget    string
+	searchMode      rune
+	prevEventType   event.Type
+	buffer          *buffer.Buffer
+	highlights      highlight.Highlights
+	options         option.Options
+	colorschemeName string
+	err             error
+	errtyp          int
+	cmdEventCh      chan event.Event
+	wmEventCh       chan event.Event
+	uiEventCh       chan event.Event
+	redrawCh        chan struct{}
+	cmdlineCh       chan event.Event
+	quitCh          chan struct{}
+	mu              *sync.Mutex
 }
 
 // NewEditor creates a new editor.
 func NewEditor(ui UI, wm Manager, cmdline Cmdline) *Editor {
 	return &Editor{
-		ui:       ui,
-		wm:       wm,
-		cmdline:  cmdline,
-		mode:     mode.Normal,
-		prevMode: mode.Normal,
+		ui:              ui,
+		wm:              wm,
+		cmdline:         cmdline,
+		mode:            mode.Normal,
+		prevMode:        mode.Normal,
+		highlights:      highlight.Default(),
+		colorschemeName: "default",
+		options:         option.Default(),
 	}
 }
 
@@ -187,9 +199,45 @@ func (e *Editor) emit(ev event.Event) (redraw bool, finish bool, err error) {
 	case event.Info:
 		e.err, e.errtyp = ev.Error, state.MessageInfo
 		redraw = true
+	case event.Hashed:
+		if ev.Buffer != nil {
+			e.buffer = ev.Buffer
+			e.wm.SetRegister(e.buffer)
+		}
+		e.err, e.errtyp = ev.Error, state.MessageInfo
+		redraw = true
+	case event.Highlight:
+		if msg, err := e.highlight(ev.Arg); err != nil {
+			e.err, e.errtyp = err, state.MessageError
+		} else if msg != "" {
+			e.err, e.errtyp = errors.New(msg), state.MessageInfo
+		}
+		redraw = true
+	case event.Set:
+		if msg, err := e.options.Set(ev.Arg); err != nil {
+			e.err, e.errtyp = err, state.MessageError
+		} else if msg != "" {
+			e.err, e.errtyp = errors.New(msg), state.MessageInfo
+		}
+		e.wm.SetOptions(e.options)
+		e.cmdline.SetOptions(e.options)
+		redraw = true
+	case event.Colorscheme:
+		if msg, err := e.colorscheme(ev.Arg); err != nil {
+			e.err, e.errtyp = err, state.MessageError
+		} else if msg != "" {
+			e.err, e.errtyp = errors.New(msg), state.MessageInfo
+		}
+		redraw = true
 	case event.Error:
 		e.err, e.errtyp = ev.Error, state.MessageError
 		redraw = true
+	case event.IncrementalSearch:
+		if e.options.IncSearch && e.mode == mode.Search {
+			e.mu.Unlock()
+			e.wm.Emit(ev)
+			return
+		}
 	case event.Redraw:
 		width, height := e.ui.Size()
 		e.wm.Resize(width, height-1)
@@ -198,6 +246,7 @@ func (e *Editor) emit(ev event.Event) (redraw bool, finish bool, err error) {
 		e.mode, e.prevMode = mode.Normal, e.mode
 		if ev.Buffer != nil {
 			e.buffer = ev.Buffer
+			e.wm.SetRegister(e.buffer)
 			if l, err := e.buffer.Len(); err != nil {
 				e.err, e.errtyp = err, state.MessageError
 			} else {
@@ -209,6 +258,7 @@ func (e *Editor) emit(ev event.Event) (redraw bool, finish bool, err error) {
 		e.err, e.errtyp = fmt.Errorf("%d (0x%x) bytes pasted", ev.Count, ev.Count), state.MessageInfo
 		redraw = true
 	default:
+		var exitSearch bool
 		switch ev.Type {
 		case event.StartInsert, event.StartInsertHead, event.StartAppend, event.StartAppendEnd:
 			e.mode, e.prevMode = mode.Insert, e.mode
@@ -220,7 +270,8 @@ func (e *Editor) emit(ev event.Event) (redraw bool, finish bool, err error) {
 			e.mode, e.prevMode = mode.Visual, e.mode
 		case event.ExitVisual:
 			e.mode, e.prevMode = mode.Normal, e.mode
-		case event.StartCmdlineCommand:
+		case event.StartCmdlineCommand, event.StartCmdlineHistoryCommand:
+			e.cmdline.SetBufferNames(e.wm.BufferNames())
 			if e.mode == mode.Visual {
 				ev.Arg = "'<,'>"
 			} else if ev.Count > 0 {
@@ -228,15 +279,16 @@ func (e *Editor) emit(ev event.Event) (redraw bool, finish bool, err error) {
 			}
 			e.mode, e.prevMode = mode.Cmdline, e.mode
 			e.err = nil
-		case event.StartCmdlineSearchForward:
+		case event.StartCmdlineSearchForward, event.StartCmdlineHistorySearchForward:
 			e.mode, e.prevMode = mode.Search, e.mode
 			e.err = nil
 			e.searchMode = '/'
-		case event.StartCmdlineSearchBackward:
+		case event.StartCmdlineSearchBackward, event.StartCmdlineHistorySearchBackward:
 			e.mode, e.prevMode = mode.Search, e.mode
 			e.err = nil
 			e.searchMode = '?'
 		case event.ExitCmdline:
+			exitSearch = e.mode == mode.Search
 			e.mode, e.prevMode = mode.Normal, e.mode
 		case event.ExecuteCmdline:
 			m := mode.Normal
@@ -246,6 +298,12 @@ func (e *Editor) emit(ev event.Event) (redraw bool, finish bool, err error) {
 			e.mode, e.prevMode = m, e.mode
 		case event.ExecuteSearch:
 			e.searchTarget, e.searchMode = ev.Arg, ev.Rune
+		case event.SearchAll:
+			e.searchTarget, e.searchMode = ev.Arg, '/'
+		case event.InsertRegisterCmdline:
+			ev.Arg = e.registerHex()
+		case event.InsertWordCmdline:
+			ev.Arg = e.cursorWordHex()
 		case event.NextSearch:
 			ev.Arg, ev.Rune, e.err = e.searchTarget, e.searchMode, nil
 		case event.PreviousSearch:
@@ -261,6 +319,10 @@ func (e *Editor) emit(ev event.Event) (redraw bool, finish bool, err error) {
 			ev.Type == event.ExitCmdline || ev.Type == event.ExecuteCmdline {
 			e.mu.Unlock()
 			e.cmdlineCh <- ev
+			if exitSearch {
+				// restores the cursor moved by the incremental search
+				e.wm.Emit(ev)
+			}
 		} else {
 			if event.ScrollUp <= ev.Type && ev.Type <= event.SwitchFocus {
 				e.prevMode, e.err = e.mode, nil
@@ -277,16 +339,98 @@ func (e *Editor) emit(ev event.Event) (redraw bool, finish bool, err error) {
 	return
 }
 
+// maxRegisterInsertSize is the maximum size of the register to be inserted
+// to the cmdline.
+const maxRegisterInsertSize = 4096
+
+// registerHex returns the bytes of the register as a hex literal.
+func (e *Editor) registerHex() string {
+	if e.buffer == nil {
+		return ""
+	}
+	l, err := e.buffer.Len()
+	if err != nil || l == 0 || l > maxRegisterInsertSize {
+		return ""
+	}
+	bs := make([]byte, l)
+	if _, err := e.buffer.ReadAt(bs, 0); err != nil && err != io.EOF {
+		return ""
+	}
+	return fmt.Sprintf("0x%x", bs)
+}
+
+// cursorWordHex returns the bytes of the word under the cursor as a hex
+// literal. The size of the word is that of the display of the window.
+func (e *Editor) cursorWordHex() string {
+	windowStates, _, windowIndex, err := e.wm.State()
+	if err != nil {
+		return ""
+	}
+	ws := windowStates[windowIndex]
+	if ws == nil {
+		return ""
+	}
+	size := int64(mathutil.MaxInt(ws.Display.Size, 1))
+	start := ws.Cursor - ws.Cursor%size - ws.Offset
+	end := mathutil.MinInt64(start+size, int64(ws.Size))
+	if start < 0 || start >= end {
+		return ""
+	}
+	return fmt.Sprintf("0x%x", ws.Bytes[start:end])
+}
+
 // Open opens a new file.
 func (e *Editor) Open(filename string) (err error) {
 	return e.wm.Open(filename)
 }
 
+// OpenSession restores the session from the file.
+func (e *Editor) OpenSession(name string) (err error) {
+	if err = e.wm.OpenSession(name); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.options = e.wm.Options()
+	e.cmdline.SetOptions(e.options)
+	return nil
+}
+
 // OpenEmpty creates a new window.
 func (e *Editor) OpenEmpty() (err error) {
 	return e.wm.Open("")
 }
 
+// LoadInfo loads the histories from the info file.
+// It is not an error that the file does not exist.
+func (e *Editor) LoadInfo(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	return e.cmdline.ReadHistory(f)
+}
+
+// SaveInfo saves the histories to the info file.
+func (e *Editor) SaveInfo(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := e.cmdline.WriteHistory(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 // Run the editor.
 func (e *Editor) Run() error {
 	if err := e.ui.Init(e.uiEventCh); err != nil {
@@ -313,6 +457,9 @@ func (e *Editor) redraw() (err error) {
 		return errors.New("index out of windows")
 	}
 	s.WindowStates[windowIndex].Mode = e.mode
+	s.Tabs, s.TabIndex = e.wm.Tabs()
+	s.Quickfix = e.wm.Quickfix()
+	s.Highlights, s.Options = e.highlights, e.options
 	s.Mode, s.PrevMode, s.Error, s.ErrorType = e.mode, e.prevMode, e.err, e.errtyp
 	if s.Mode != mode.Visual && s.PrevMode != mode.Visual {
 		for _, ws := range s.WindowStates {
@@ -320,6 +467,7 @@ func (e *Editor) redraw() (err error) {
 		}
 	}
 	s.Cmdline, s.CmdlineCursor, s.CompletionResults, s.CompletionIndex = e.cmdline.Get()
+	s.HistoryEntries, s.HistoryIndex = e.cmdline.GetHistory()
 	if e.mode == mode.Search || e.prevEventType == event.ExecuteSearch {
 		s.SearchMode = e.searchMode
 	} else if e.prevEventType == event.NextSearch {
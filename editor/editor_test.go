@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/itchyny/bed/buffer"
 	"github.com/itchyny/bed/cmdline"
 	"github.com/itchyny/bed/event"
 	"github.com/itchyny/bed/key"
@@ -423,6 +425,49 @@ func TestEditorReplace(t *testing.T) {
 	}
 }
 
+func TestEditorSetEncoding(t *testing.T) {
+	f, _ := ioutil.TempFile("", "bed-test-editor-set-encoding")
+	defer os.Remove(f.Name())
+	_ = f.Close()
+	ui := newTestUI()
+	editor := NewEditor(ui, window.NewManager(), cmdline.NewCmdline())
+	if err := editor.Init(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if err := editor.Open(f.Name()); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	go func() {
+		for _, e := range []struct {
+			typ event.Type
+			ch  rune
+			arg string
+		}{
+			{event.Set, '\x00', "encoding=utf-16be"}, {event.StartInsert, 'i', ""},
+			{event.SwitchFocus, '\x00', ""}, {event.Rune, 'A', ""}, {event.Rune, 'あ', ""},
+			{event.ExitInsert, '\x00', ""}, {event.Set, '\x00', "enc=ebcdic"},
+			{event.StartAppendEnd, 'A', ""}, {event.Rune, 'A', ""}, {event.ExitInsert, '\x00', ""},
+			{event.Write, 'w', ""},
+		} {
+			ui.Emit(event.Event{Type: e.typ, Rune: e.ch, Arg: e.arg})
+		}
+		ui.Emit(event.Event{Type: event.Quit, Bang: true})
+	}()
+	if err := editor.Run(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if editor.options.Encoding != "ebcdic" {
+		t.Errorf("encoding should be %q but got %q", "ebcdic", editor.options.Encoding)
+	}
+	if err := editor.Close(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	bs, _ := ioutil.ReadFile(f.Name())
+	if expected := "\x00A\x30\x42\xc1"; string(bs) != expected {
+		t.Errorf("file contents should be %q but got %q", expected, string(bs))
+	}
+}
+
 func TestEditorCopyCutPaste(t *testing.T) {
 	f1, _ := ioutil.TempFile("", "bed-test-editor-copy-cut-paste1")
 	f2, _ := ioutil.TempFile("", "bed-test-editor-copy-cut-paste2")
@@ -638,3 +683,143 @@ func TestEditorCmdlineQuitErr(t *testing.T) {
 		t.Errorf("err should be nil but got: %v", err)
 	}
 }
+
+func TestEditorHighlightColorscheme(t *testing.T) {
+	editor := NewEditor(newTestUI(), window.NewManager(), cmdline.NewCmdline())
+	if err := editor.Init(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if msg, err := editor.highlight("Offset fg=blue attr=bold"); msg != "" || err != nil {
+		t.Errorf("highlight should return empty message but got %q, %v", msg, err)
+	}
+	if msg, _ := editor.highlight("Offset"); msg != "Offset           fg=blue attr=bold" {
+		t.Errorf("highlight should show the group but got %q", msg)
+	}
+	if _, err := editor.highlight("Offset fg=foo"); err == nil {
+		t.Errorf("highlight should return error for invalid color")
+	}
+	f, err := ioutil.TempFile("", "bed-test-editor-colorscheme")
+	if err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("highlight Offset fg=green\n"); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if _, err := editor.colorscheme(f.Name()); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if msg, _ := editor.highlight("Offset"); msg != "Offset           fg=green" {
+		t.Errorf("colorscheme should set the group but got %q", msg)
+	}
+	if msg, _ := editor.colorscheme(""); msg != f.Name() {
+		t.Errorf("colorscheme should return the name but got %q", msg)
+	}
+	if _, err := editor.colorscheme("plain"); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if msg, _ := editor.highlight("Offset"); msg != "Offset           cleared" {
+		t.Errorf("colorscheme should set the group but got %q", msg)
+	}
+	if _, err := editor.colorscheme("unknown"); err == nil || err.Error() != "cannot find color scheme: unknown" {
+		t.Errorf("colorscheme should return error but got %v", err)
+	}
+}
+
+func TestEditorInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bed-test-editor-info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "bed", "bedinfo")
+	ui := newTestUI()
+	editor := NewEditor(ui, window.NewManager(), cmdline.NewCmdline())
+	if err := editor.Init(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if err := editor.LoadInfo(path); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if err := editor.OpenEmpty(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	go func() {
+		ui.Emit(event.Event{Type: event.StartCmdlineCommand})
+		for _, c := range "set ob=dec" {
+			ui.Emit(event.Event{Type: event.Rune, Rune: c})
+		}
+		ui.Emit(event.Event{Type: event.ExecuteCmdline})
+		ui.Emit(event.Event{Type: event.StartCmdlineSearchForward})
+		for _, c := range "abc" {
+			ui.Emit(event.Event{Type: event.Rune, Rune: c})
+		}
+		ui.Emit(event.Event{Type: event.ExecuteCmdline})
+		ui.Emit(event.Event{Type: event.Quit, Bang: true})
+	}()
+	if err := editor.Run(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if err := editor.Close(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if err := editor.SaveInfo(path); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if !strings.Contains(string(bs), "\n:set ob=dec\n") || !strings.Contains(string(bs), "\n/abc\n") {
+		t.Errorf("info file should contain the histories but got %q", string(bs))
+	}
+
+	c := cmdline.NewCmdline()
+	editor = NewEditor(newTestUI(), window.NewManager(), c)
+	if err := editor.LoadInfo(path); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	var b strings.Builder
+	if err := c.WriteHistory(&b); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if b.String() != string(bs) {
+		t.Errorf("histories should be loaded from the info file but got %q", b.String())
+	}
+}
+
+func TestEditorCmdlineInsertHex(t *testing.T) {
+	f, err := ioutil.TempFile("", "bed-test-editor-cmdline-insert-hex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("\x12\x34\x56\x78"); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	_ = f.Close()
+	editor := NewEditor(newTestUI(), window.NewManager(), cmdline.NewCmdline())
+	if err := editor.Init(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	if err := editor.Open(f.Name()); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	editor.wm.Resize(90, 19)
+	if got := editor.registerHex(); got != "" {
+		t.Errorf("registerHex should be empty but got %q", got)
+	}
+	editor.buffer = buffer.NewBuffer(strings.NewReader("AB\x00"))
+	if got, expected := editor.registerHex(), "0x414200"; got != expected {
+		t.Errorf("registerHex should be %q but got %q", expected, got)
+	}
+	if got, expected := editor.cursorWordHex(), "0x12"; got != expected {
+		t.Errorf("cursorWordHex should be %q but got %q", expected, got)
+	}
+	if err := editor.Close(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/itchyny/bed/highlight"
+	"github.com/itchyny/bed/pathutil"
+)
+
+// highlight sets the highlight group, or shows the highlight groups
+// when the argument has no color and attribute.
+func (e *Editor) highlight(arg string) (string, error) {
+	if xs := strings.Fields(arg); len(xs) <= 1 && (len(xs) == 0 || xs[0] != "clear") {
+		return e.highlights.Show(arg)
+	}
+	return "", e.highlights.Set(arg)
+}
+
+// colorscheme loads the color scheme of the name, or shows the name of the
+// current color scheme. The color scheme is one of the built-in schemes,
+// the file of the path or the file in the colors directory of the config.
+func (e *Editor) colorscheme(arg string) (string, error) {
+	name := strings.TrimSpace(arg)
+	if name == "" {
+		return e.colorschemeName, nil
+	}
+	if hs, ok := highlight.ColorScheme(name); ok {
+		e.highlights, e.colorschemeName = hs, name
+		return "", nil
+	}
+	path, err := colorschemePath(name)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hs := highlight.Default()
+	if err := hs.Load(f); err != nil {
+		return "", fmt.Errorf("%s: %s", path, err)
+	}
+	e.highlights, e.colorschemeName = hs, name
+	return "", nil
+}
+
+func colorschemePath(name string) (string, error) {
+	if strings.ContainsRune(name, filepath.Separator) || strings.HasPrefix(name, "~") {
+		return pathutil.HomedirExpand(name)
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "bed", "colors", name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("cannot find color scheme: %s", name)
+	}
+	return path, nil
+}
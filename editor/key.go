@@ -18,6 +18,9 @@ func defaultKeyManagers() map[mode.Mode]*key.Manager {
 
 	km.Register(event.JumpTo, "\x1d")
 	km.Register(event.JumpBack, "c-t")
+	for c := 'a'; c <= 'z'; c++ {
+		km.Register(event.SetMark, "m", key.Key(c))
+	}
 	km.Register(event.DeleteByte, "x")
 	km.Register(event.DeleteByte, "delete")
 	km.Register(event.DeletePrevByte, "X")
@@ -70,6 +73,21 @@ func defaultKeyManagers() map[mode.Mode]*key.Manager {
 	km.Register(event.MoveWindowBottom, "c-w", "J")
 	km.Register(event.MoveWindowLeft, "c-w", "H")
 	km.Register(event.MoveWindowRight, "c-w", "L")
+	km.Register(event.RotateWindowsDownwards, "c-w", "r")
+	km.Register(event.RotateWindowsDownwards, "c-w", "c-r")
+	km.Register(event.RotateWindowsUpwards, "c-w", "R")
+	km.Register(event.ExchangeWindow, "c-w", "x")
+	km.Register(event.ExchangeWindow, "c-w", "c-x")
+	km.Register(event.OnlyWindow, "c-w", "o")
+	km.Register(event.OnlyWindow, "c-w", "c-o")
+	km.Register(event.IncreaseWindowHeight, "c-w", "+")
+	km.Register(event.DecreaseWindowHeight, "c-w", "-")
+	km.Register(event.IncreaseWindowWidth, "c-w", ">")
+	km.Register(event.DecreaseWindowWidth, "c-w", "<")
+	km.Register(event.EqualizeWindows, "c-w", "=")
+	km.Register(event.MaximizeWindowHeight, "c-w", "_")
+	km.Register(event.MaximizeWindowWidth, "c-w", "|")
+	km.Register(event.QuickfixSelect, "enter")
 	kms[mode.Normal] = km
 
 	km = key.NewManager(false)
@@ -90,6 +108,7 @@ func defaultKeyManagers() map[mode.Mode]*key.Manager {
 	km.Register(event.Backspace, "backspace")
 	km.Register(event.Backspace, "backspace2")
 	km.Register(event.Delete, "delete")
+	km.Register(event.StartLiteral, "c-v")
 	km.Register(event.SwitchFocus, "tab")
 	km.Register(event.SwitchFocus, "backtab")
 	kms[mode.Insert] = km
@@ -122,12 +141,21 @@ func defaultKeyManagers() map[mode.Mode]*key.Manager {
 	km.Register(event.BackspaceCmdline, "backspace2")
 	km.Register(event.DeleteCmdline, "delete")
 	km.Register(event.DeleteWordCmdline, "c-w")
+	km.Register(event.WordBackwardCmdline, "s-left")
+	km.Register(event.WordBackwardCmdline, "m-b")
+	km.Register(event.WordForwardCmdline, "s-right")
+	km.Register(event.WordForwardCmdline, "m-f")
 	km.Register(event.ClearToHeadCmdline, "c-u")
-	km.Register(event.ClearCmdline, "c-k")
+	km.Register(event.ClearToEndCmdline, "c-k")
+	km.Register(event.YankCmdline, "c-y")
+	km.Register(event.InsertRegisterCmdline, "c-r", "\"")
+	km.Register(event.InsertWordCmdline, "c-r", "c-w")
 	km.Register(event.ExitCmdline, "escape")
 	km.Register(event.ExitCmdline, "c-c")
 	km.Register(event.CompleteForwardCmdline, "tab")
 	km.Register(event.CompleteBackCmdline, "backtab")
+	km.Register(event.HistoryPrevCmdline, "up")
+	km.Register(event.HistoryNextCmdline, "down")
 	km.Register(event.ExecuteCmdline, "enter")
 	km.Register(event.ExecuteCmdline, "c-j")
 	km.Register(event.ExecuteCmdline, "c-m")
@@ -175,6 +203,8 @@ func defaultNormalAndVisual() *key.Manager {
 	km.Register(event.PageUpHalf, "c-u")
 	km.Register(event.PageDownHalf, "c-d")
 	km.Register(event.PageTop, "g", "g")
+	km.Register(event.TabNext, "g", "t")
+	km.Register(event.TabPrevious, "g", "T")
 	km.Register(event.PageEnd, "G")
 
 	km.Register(event.SwitchFocus, "tab")
@@ -187,6 +217,9 @@ func defaultNormalAndVisual() *key.Manager {
 	km.Register(event.AbortSearch, "c-c")
 
 	km.Register(event.StartCmdlineCommand, ":")
+	km.Register(event.StartCmdlineHistoryCommand, "q", ":")
+	km.Register(event.StartCmdlineHistorySearchForward, "q", "/")
+	km.Register(event.StartCmdlineHistorySearchBackward, "q", "?")
 	km.Register(event.StartReplaceByte, "r")
 	return km
 }
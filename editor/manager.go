@@ -1,8 +1,10 @@
 package editor
 
 import (
+	"github.com/itchyny/bed/buffer"
 	"github.com/itchyny/bed/event"
 	"github.com/itchyny/bed/layout"
+	"github.com/itchyny/bed/option"
 	"github.com/itchyny/bed/state"
 )
 
@@ -10,9 +12,16 @@ import (
 type Manager interface {
 	Init(chan<- event.Event, chan<- struct{})
 	Open(string) error
+	OpenSession(string) error
 	SetSize(int, int)
 	Resize(int, int)
 	Emit(event.Event)
 	State() (map[int]*state.WindowState, layout.Layout, int, error)
+	Tabs() ([]state.TabState, int)
+	Quickfix() *state.QuickfixState
+	BufferNames() []string
+	Options() option.Options
+	SetOptions(option.Options)
+	SetRegister(*buffer.Buffer)
 	Close()
 }
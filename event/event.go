@@ -55,6 +55,7 @@ const (
 	WindowBottom
 	JumpTo
 	JumpBack
+	SetMark
 
 	DeleteByte
 	DeletePrevByte
@@ -77,6 +78,7 @@ const (
 	Backspace
 	Delete
 	Rune
+	StartLiteral
 
 	Undo
 	Redo
@@ -91,29 +93,67 @@ const (
 	Paste
 	PastePrev
 	Pasted
+	Fill
+	Read
+	Filter
+	Filtered
+	Hash
+	Hashed
+	ChecksumFix
+	Display
 
 	StartCmdlineCommand
 	StartCmdlineSearchForward
 	StartCmdlineSearchBackward
+	StartCmdlineHistoryCommand
+	StartCmdlineHistorySearchForward
+	StartCmdlineHistorySearchBackward
 	BackspaceCmdline
 	DeleteCmdline
 	DeleteWordCmdline
+	WordBackwardCmdline
+	WordForwardCmdline
 	ClearToHeadCmdline
+	ClearToEndCmdline
 	ClearCmdline
+	YankCmdline
+	InsertRegisterCmdline
+	InsertWordCmdline
 	ExitCmdline
 	CompleteForwardCmdline
 	CompleteBackCmdline
+	HistoryPrevCmdline
+	HistoryNextCmdline
 	ExecuteCmdline
 	ExecuteSearch
+	IncrementalSearch
 	NextSearch
 	PreviousSearch
 	AbortSearch
+	SearchAll
+	FindAll
+	Strings
+	QuickfixOpen
+	QuickfixClose
+	QuickfixNext
+	QuickfixPrevious
+	QuickfixGoto
+	QuickfixSelect
 
 	Edit
 	Enew
 	New
 	Vnew
 	Alternative
+	Buffers
+	Buffer
+	BufferNext
+	BufferPrevious
+	BufferDelete
+	MakeSession
+	Highlight
+	Colorscheme
+	Set
 	Wincmd
 	FocusWindowUp
 	FocusWindowDown
@@ -126,6 +166,24 @@ const (
 	MoveWindowBottom
 	MoveWindowLeft
 	MoveWindowRight
+	RotateWindowsDownwards
+	RotateWindowsUpwards
+	ExchangeWindow
+	OnlyWindow
+	IncreaseWindowHeight
+	DecreaseWindowHeight
+	IncreaseWindowWidth
+	DecreaseWindowWidth
+	EqualizeWindows
+	MaximizeWindowHeight
+	MaximizeWindowWidth
+	Resize
+	VerticalResize
+	TabNew
+	TabClose
+	TabNext
+	TabPrevious
+	TabMove
 	Suspend
 	Quit
 	QuitAll
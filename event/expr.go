@@ -0,0 +1,273 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+)
+
+// Env is the environment to evaluate expressions.
+type Env interface {
+	io.ReaderAt
+	Cursor() int64
+	End() int64
+	Mark(rune) (int64, error)
+}
+
+type expr interface {
+	eval(Env) (int64, error)
+}
+
+type numberExpr int64
+
+func (e numberExpr) eval(Env) (int64, error) {
+	return int64(e), nil
+}
+
+type cursorExpr struct{}
+
+func (e cursorExpr) eval(env Env) (int64, error) {
+	return env.Cursor(), nil
+}
+
+type endExpr struct{}
+
+func (e endExpr) eval(env Env) (int64, error) {
+	return env.End(), nil
+}
+
+type markExpr rune
+
+func (e markExpr) eval(env Env) (int64, error) {
+	return env.Mark(rune(e))
+}
+
+type unaryExpr struct {
+	op rune
+	x  expr
+}
+
+func (e unaryExpr) eval(env Env) (int64, error) {
+	x, err := e.x.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	if e.op == '-' {
+		return -x, nil
+	}
+	return x, nil
+}
+
+type binaryExpr struct {
+	op   rune
+	x, y expr
+}
+
+func (e binaryExpr) eval(env Env) (int64, error) {
+	x, err := e.x.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	y, err := e.y.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	switch e.op {
+	case '+':
+		return x + y, nil
+	case '-':
+		return x - y, nil
+	case '*':
+		return x * y, nil
+	case '/', '%':
+		if y == 0 {
+			return 0, errors.New("division by zero")
+		}
+		if e.op == '/' {
+			return x / y, nil
+		}
+		return x % y, nil
+	default:
+		panic("event.binaryExpr.eval: unreachable")
+	}
+}
+
+type callExpr struct {
+	name string
+	arg  expr
+}
+
+var functions = map[string]struct {
+	size      int
+	bigEndian bool
+	signed    bool
+}{
+	"u8":    {1, false, false},
+	"i8":    {1, false, true},
+	"u16le": {2, false, false},
+	"u16be": {2, true, false},
+	"i16le": {2, false, true},
+	"i16be": {2, true, true},
+	"u32le": {4, false, false},
+	"u32be": {4, true, false},
+	"i32le": {4, false, true},
+	"i32be": {4, true, true},
+	"u64le": {8, false, false},
+	"u64be": {8, true, false},
+	"i64le": {8, false, true},
+	"i64be": {8, true, true},
+}
+
+func (e callExpr) eval(env Env) (int64, error) {
+	offset, err := e.arg.eval(env)
+	if err != nil {
+		return 0, err
+	}
+	f := functions[e.name]
+	bs := make([]byte, f.size)
+	if n, err := env.ReadAt(bs, offset); n < f.size {
+		if err == nil || err == io.EOF {
+			err = fmt.Errorf("%s: cannot read %d bytes at 0x%x", e.name, f.size, offset)
+		}
+		return 0, err
+	}
+	var x uint64
+	for i := range bs {
+		if f.bigEndian {
+			x = x<<8 | uint64(bs[i])
+		} else {
+			x = x<<8 | uint64(bs[f.size-1-i])
+		}
+	}
+	if f.signed && f.size < 8 {
+		shift := uint(64 - 8*f.size)
+		return int64(x<<shift) >> shift, nil
+	}
+	return int64(x), nil
+}
+
+// parseExpr parses an expression and returns the next index.
+// Returns nil if no expression is found at the index.
+//    expr    := term { [+-] term }
+//    term    := unary { [*/%] unary }
+//    unary   := [+-] unary | primary
+//    primary := num | . | $ | ' [a-z<>] | func ( expr ) | ( expr )
+func parseExpr(xs []rune, i int) (expr, int) {
+	x, i := parseTerm(xs, i)
+	if x == nil {
+		return nil, i
+	}
+	for {
+		j := skipSpaces(xs, i)
+		if j >= len(xs) || xs[j] != '+' && xs[j] != '-' {
+			return x, i
+		}
+		y, k := parseTerm(xs, j+1)
+		if y == nil {
+			return x, i
+		}
+		x, i = binaryExpr{xs[j], x, y}, k
+	}
+}
+
+func parseTerm(xs []rune, i int) (expr, int) {
+	x, i := parseUnary(xs, i)
+	if x == nil {
+		return nil, i
+	}
+	for {
+		j := skipSpaces(xs, i)
+		if j >= len(xs) || xs[j] != '*' && xs[j] != '/' && xs[j] != '%' {
+			return x, i
+		}
+		y, k := parseUnary(xs, j+1)
+		if y == nil {
+			return x, i
+		}
+		x, i = binaryExpr{xs[j], x, y}, k
+	}
+}
+
+func parseUnary(xs []rune, i int) (expr, int) {
+	j := skipSpaces(xs, i)
+	if j < len(xs) && (xs[j] == '+' || xs[j] == '-') {
+		x, k := parseUnary(xs, j+1)
+		if x == nil {
+			return nil, i
+		}
+		return unaryExpr{xs[j], x}, k
+	}
+	return parsePrimary(xs, i)
+}
+
+func parsePrimary(xs []rune, i int) (expr, int) {
+	j := skipSpaces(xs, i)
+	if j >= len(xs) {
+		return nil, i
+	}
+	switch c := xs[j]; {
+	case '0' <= c && c <= '9':
+		n, k := parseNum(xs, j)
+		return numberExpr(n), k + 1
+	case c == '.':
+		return cursorExpr{}, j + 1
+	case c == '$':
+		return endExpr{}, j + 1
+	case c == '\'':
+		if j+1 < len(xs) && ('a' <= xs[j+1] && xs[j+1] <= 'z' || xs[j+1] == '<' || xs[j+1] == '>') {
+			return markExpr(xs[j+1]), j + 2
+		}
+	case c == '(':
+		x, k := parseExpr(xs, j+1)
+		if k = skipSpaces(xs, k); x == nil || k >= len(xs) || xs[k] != ')' {
+			return nil, i
+		}
+		return x, k + 1
+	case 'a' <= c && c <= 'z':
+		k := j
+		for k < len(xs) && ('a' <= xs[k] && xs[k] <= 'z' || '0' <= xs[k] && xs[k] <= '9') {
+			k++
+		}
+		if _, ok := functions[string(xs[j:k])]; !ok || k >= len(xs) || xs[k] != '(' {
+			return nil, i
+		}
+		x, l := parsePrimary(xs, k)
+		if x == nil {
+			return nil, i
+		}
+		return callExpr{string(xs[j:k]), x}, l
+	}
+	return nil, i
+}
+
+func skipSpaces(xs []rune, i int) int {
+	for i < len(xs) && unicode.IsSpace(xs[i]) {
+		i++
+	}
+	return i
+}
+
+// constant folds the expression if it does not depend on the environment.
+func constant(e expr) (int64, bool) {
+	switch e := e.(type) {
+	case numberExpr:
+		return int64(e), true
+	case unaryExpr:
+		if x, ok := constant(e.x); ok {
+			if e.op == '-' {
+				return -x, true
+			}
+			return x, true
+		}
+	case binaryExpr:
+		if x, ok := constant(e.x); ok {
+			if y, ok := constant(e.y); ok {
+				if z, err := (binaryExpr{e.op, numberExpr(x), numberExpr(y)}).eval(nil); err == nil {
+					return z, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
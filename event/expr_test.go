@@ -0,0 +1,114 @@
+package event
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testEnv struct {
+	*bytes.Reader
+	cursor int64
+	marks  map[rune]int64
+}
+
+func (env testEnv) Cursor() int64 {
+	return env.cursor
+}
+
+func (env testEnv) End() int64 {
+	return env.Size() - 1
+}
+
+func (env testEnv) Mark(name rune) (int64, error) {
+	if offset, ok := env.marks[name]; ok {
+		return offset, nil
+	}
+	return 0, errors.New("mark not set")
+}
+
+func TestParsePosExpression(t *testing.T) {
+	testCases := []struct {
+		target   string
+		expected Position
+		index    int
+	}{
+		{"2*3+4", Absolute{10}, 5},
+		{"(1+2)*(3+4)", Absolute{21}, 11},
+		{"0x100/0x10 w", Absolute{16}, 11},
+		{"17 % 5", Absolute{2}, 6},
+		{"-(2*8)", Relative{-16}, 6},
+		{". + 4 * 4", Relative{16}, 9},
+		{"$-4*4", End{-16}, 5},
+		{"'<+3*2", VisualStart{6}, 6},
+		{"'a", Expression{markExpr('a')}, 2},
+		{"$/2", Expression{binaryExpr{'/', endExpr{}, numberExpr(2)}}, 3},
+		{"0x40 + u32le(0x3c)", Expression{binaryExpr{'+', numberExpr(0x40),
+			callExpr{"u32le", numberExpr(0x3c)}}}, 18},
+		{"u8(.),$", Expression{callExpr{"u8", cursorExpr{}}}, 5},
+		{"1+", Absolute{1}, 1},
+		{"(1+2", nil, 0},
+		{"foo(1)", nil, 0},
+		{"write", nil, 0},
+	}
+	for _, testCase := range testCases {
+		got, gotIndex := ParsePos([]rune(testCase.target), 0)
+		if !reflect.DeepEqual(got, testCase.expected) {
+			t.Errorf("ParsePos(%q) should return %#v but got %#v", testCase.target, testCase.expected, got)
+		}
+		if gotIndex != testCase.index {
+			t.Errorf("ParsePos(%q) should return index %d but got %d", testCase.target, testCase.index, gotIndex)
+		}
+	}
+}
+
+func TestExpressionEval(t *testing.T) {
+	env := testEnv{
+		Reader: bytes.NewReader([]byte("\x10\x20\x30\x40\x50\x60\x70\x80\xff\xff\xff\xff")),
+		cursor: 2,
+		marks:  map[rune]int64{'a': 4},
+	}
+	testCases := []struct {
+		target   string
+		expected int64
+		err      string
+	}{
+		{".*3", 6, ""},
+		{"$/2", 5, ""},
+		{"'a+.", 6, ""},
+		{"'b", 0, "mark not set"},
+		{"u8(.)", 0x30, ""},
+		{"u16le(0)", 0x2010, ""},
+		{"u16be(0)", 0x1020, ""},
+		{"u32le('a)", 0x80706050, ""},
+		{"u32be(1)", 0x20304050, ""},
+		{"u64be(0)", 0x1020304050607080, ""},
+		{"i8($)", -1, ""},
+		{"i32le(8)", -1, ""},
+		{"u32le($)", 0, "u32le: cannot read 4 bytes at 0xb"},
+		{"1/(.-2)", 0, "division by zero"},
+		{"$%(.-2)", 0, "division by zero"},
+	}
+	for _, testCase := range testCases {
+		pos, _ := ParsePos([]rune(testCase.target), 0)
+		p, ok := pos.(Expression)
+		if !ok {
+			t.Errorf("ParsePos(%q) should return Expression but got %#v", testCase.target, pos)
+			continue
+		}
+		got, err := p.Eval(env)
+		if testCase.err != "" {
+			if err == nil || err.Error() != testCase.err {
+				t.Errorf("Eval(%q) should return error %q but got %v", testCase.target, testCase.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Eval(%q) should not return error but got %v", testCase.target, err)
+		}
+		if got != testCase.expected {
+			t.Errorf("Eval(%q) should return %d but got %d", testCase.target, testCase.expected, got)
+		}
+	}
+}
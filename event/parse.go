@@ -1,7 +1,5 @@
 package event
 
-import "unicode"
-
 // ParseRange parses a Range.
 func ParseRange(xs []rune, i int) (*Range, int) {
 	from, i := ParsePos(xs, i)
@@ -15,72 +13,82 @@ func ParseRange(xs []rune, i int) (*Range, int) {
 	return &Range{From: from, To: to}, i
 }
 
-var states = map[int]map[rune]struct {
-	position Position
-	state    int
-}{
-	0: {
-		'$':  {position: End{}, state: 1},
-		'.':  {position: Relative{}, state: 1},
-		'\'': {position: nil, state: 2},
-	},
-	2: {
-		'<': {position: VisualStart{}, state: 1},
-		'>': {position: VisualEnd{}, state: 1},
-	},
-}
-
 // ParsePos parses a Position.
 //    +---- num.. ----+
-//    +-- [-+]num.. --+   +---------------+
-//    +------ $ ------+   |               |
-// ---+------ . ------+---+-- [-+]num.. --+---
-//    +-- ' -+- < -+--+
-//           +- > -+
+//    +------ $ ------+   +-----------------------+
+//    +------ . ------+   |                       |
+// ---+-- ' -+- < -+--+---+-- [-+*/%] -- term --+-+---
+//    |      +- > -+  |
+//    |      +- a -+  |
+//    +-- [-+] term --+
+//    +-- func(..) ---+
+//    +---- (..) -----+
+// A position starting with a sign is relative to the cursor.
+// The position is an Expression unless it is a simple offset
+// from the cursor, the end, or the visual selection.
 func ParsePos(xs []rune, i int) (Position, int) {
-	var state int
-	var position Position
-	for ; i < len(xs); i++ {
-		if state <= 1 && unicode.IsSpace(xs[i]) {
-			continue
-		}
-		if state == 0 && '0' <= xs[i] && xs[i] <= '9' {
-			var offset int64
-			offset, i = parseNum(xs, i)
-			if position == nil {
-				position = Absolute{offset}
-			}
-			state = 1
-			continue
+	i = skipSpaces(xs, i)
+	x, j := parseExpr(xs, i)
+	if x == nil {
+		return nil, i
+	}
+	if xs[i] == '+' || xs[i] == '-' {
+		x = binaryExpr{'+', cursorExpr{}, x}
+	}
+	return simplify(x), skipSpaces(xs, j)
+}
+
+func simplify(x expr) Position {
+	var base Position = Absolute{}
+	var offset int64
+	var found bool
+	var walk func(expr, int64) bool
+	walk = func(x expr, sign int64) bool {
+		if c, ok := constant(x); ok {
+			offset += sign * c
+			return true
 		}
-		if state <= 1 && (xs[i] == '+' || xs[i] == '-') {
-			var offset int64
-			sign := int64(1)
-			if xs[i] == '-' {
-				sign = -1
+		switch x := x.(type) {
+		case unaryExpr:
+			if x.op == '-' {
+				sign = -sign
 			}
-			offset, i = parseNum(xs, i+1)
-			offset *= sign
-			if position == nil {
-				position = Relative{offset}
-			} else {
-				position = position.addOffset(offset)
+			return walk(x.x, sign)
+		case binaryExpr:
+			switch x.op {
+			case '+':
+				return walk(x.x, sign) && walk(x.y, sign)
+			case '-':
+				return walk(x.x, sign) && walk(x.y, -sign)
 			}
-			state = 1
-			continue
 		}
-		if s, ok := states[state]; ok {
-			if next, ok := s[xs[i]]; ok {
-				state = next.state
-				position = next.position
-			} else {
-				return position, i
+		if found || sign < 0 {
+			return false
+		}
+		switch x := x.(type) {
+		case cursorExpr:
+			base = Relative{}
+		case endExpr:
+			base = End{}
+		case markExpr:
+			switch x {
+			case '<':
+				base = VisualStart{}
+			case '>':
+				base = VisualEnd{}
+			default:
+				return false
 			}
-		} else {
-			return position, i
+		default:
+			return false
 		}
+		found = true
+		return true
+	}
+	if !walk(x, 1) {
+		return Expression{x}
 	}
-	return position, i
+	return base.addOffset(offset)
 }
 
 func parseNum(xs []rune, i int) (int64, int) {
@@ -66,3 +66,19 @@ func (p VisualEnd) isPosition() {}
 func (p VisualEnd) addOffset(offset int64) Position {
 	return VisualEnd{p.Offset + offset}
 }
+
+// Expression is the position calculated by an expression.
+type Expression struct {
+	expr expr
+}
+
+func (p Expression) isPosition() {}
+
+func (p Expression) addOffset(offset int64) Position {
+	return Expression{binaryExpr{'+', p.expr, numberExpr(offset)}}
+}
+
+// Eval evaluates the expression in the environment.
+func (p Expression) Eval(env Env) (int64, error) {
+	return p.expr.eval(env)
+}
@@ -0,0 +1,353 @@
+package highlight
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell"
+)
+
+// Highlight groups
+const (
+	Offset          = "Offset"
+	CursorOffset    = "CursorOffset"
+	Header          = "Header"
+	Separator       = "Separator"
+	HexByte         = "HexByte"
+	TextByte        = "TextByte"
+	NullByte        = "NullByte"
+	FFByte          = "FFByte"
+	AsciiPrintable  = "AsciiPrintable"
+	AsciiWhitespace = "AsciiWhitespace"
+	AsciiOther      = "AsciiOther"
+	NonAscii        = "NonAscii"
+	Edited          = "Edited"
+	Cursor          = "Cursor"
+	CursorNC        = "CursorNC"
+	CursorLine      = "CursorLine"
+	CursorColumn    = "CursorColumn"
+	Visual          = "Visual"
+	Search          = "Search"
+	ScrollBar       = "ScrollBar"
+	StatusLine      = "StatusLine"
+	StatusLineNC    = "StatusLineNC"
+	VertSplit       = "VertSplit"
+	TabLine         = "TabLine"
+	TabLineSel      = "TabLineSel"
+	ErrorMsg        = "ErrorMsg"
+	InfoMsg         = "InfoMsg"
+	Pmenu           = "Pmenu"
+	PmenuSel        = "PmenuSel"
+)
+
+// Groups is the list of the highlight groups.
+var Groups = []string{
+	Offset, CursorOffset, Header, Separator, HexByte, TextByte,
+	NullByte, FFByte, AsciiPrintable, AsciiWhitespace, AsciiOther, NonAscii,
+	Edited, Cursor, CursorNC, CursorLine, CursorColumn, Visual, Search, ScrollBar,
+	StatusLine, StatusLineNC, VertSplit, TabLine, TabLineSel,
+	ErrorMsg, InfoMsg, Pmenu, PmenuSel,
+}
+
+// Attr is the attributes of the highlight.
+type Attr int
+
+// Attributes
+const (
+	AttrBold Attr = 1 << iota
+	AttrUnderline
+	AttrReverse
+	AttrDim
+	AttrBlink
+)
+
+var attrNames = []string{"bold", "underline", "reverse", "dim", "blink"}
+
+// Highlight holds the colors and the attributes of a highlight group.
+// The colors are the names of tcell, #rrggbb or the palette numbers,
+// and empty for the default color of the terminal.
+type Highlight struct {
+	Foreground string
+	Background string
+	Attr       Attr
+}
+
+// Merge overrides the highlight with the colors and attributes of the other.
+func (h Highlight) Merge(other Highlight) Highlight {
+	if other.Foreground != "" {
+		h.Foreground = other.Foreground
+	}
+	if other.Background != "" {
+		h.Background = other.Background
+	}
+	h.Attr |= other.Attr
+	return h
+}
+
+// Style returns the style for the terminal.
+func (h Highlight) Style() tcell.Style {
+	return tcell.StyleDefault.
+		Foreground(getColor(h.Foreground)).
+		Background(getColor(h.Background)).
+		Bold(h.Attr&AttrBold != 0).
+		Underline(h.Attr&AttrUnderline != 0).
+		Reverse(h.Attr&AttrReverse != 0).
+		Dim(h.Attr&AttrDim != 0).
+		Blink(h.Attr&AttrBlink != 0)
+}
+
+func (h Highlight) String() string {
+	var xs []string
+	if h.Foreground != "" {
+		xs = append(xs, "fg="+h.Foreground)
+	}
+	if h.Background != "" {
+		xs = append(xs, "bg="+h.Background)
+	}
+	var attrs []string
+	for i, name := range attrNames {
+		if h.Attr&(1<<uint(i)) != 0 {
+			attrs = append(attrs, name)
+		}
+	}
+	if len(attrs) > 0 {
+		xs = append(xs, "attr="+strings.Join(attrs, ","))
+	}
+	if len(xs) == 0 {
+		return "cleared"
+	}
+	return strings.Join(xs, " ")
+}
+
+func getColor(name string) tcell.Color {
+	if n, err := strconv.Atoi(name); err == nil {
+		return tcell.Color(n)
+	}
+	return tcell.GetColor(name)
+}
+
+func validColor(name string) bool {
+	if n, err := strconv.Atoi(name); err == nil {
+		return 0 <= n && n < 256
+	}
+	return getColor(name) != tcell.ColorDefault
+}
+
+// Highlights maps the highlight groups to the highlights.
+type Highlights map[string]Highlight
+
+// Clone the highlights.
+func (hs Highlights) Clone() Highlights {
+	xs := make(Highlights, len(hs))
+	for group, h := range hs {
+		xs[group] = h
+	}
+	return xs
+}
+
+// Set the highlight by the arguments of the highlight command;
+// {group} [fg={color}] [bg={color}] [attr={attr}[,{attr}]...]
+// where NONE clears the color or the attributes. With clear,
+// resets all the groups or the group to the default.
+func (hs Highlights) Set(arg string) error {
+	xs := strings.Fields(arg)
+	if len(xs) == 0 {
+		return nil
+	}
+	if xs[0] == "clear" {
+		switch len(xs) {
+		case 1:
+			for group, h := range defaultHighlights {
+				hs[group] = h
+			}
+		case 2:
+			group, err := lookupGroup(xs[1])
+			if err != nil {
+				return err
+			}
+			hs[group] = defaultHighlights[group]
+		default:
+			return fmt.Errorf("too many arguments: %s", arg)
+		}
+		return nil
+	}
+	group, err := lookupGroup(xs[0])
+	if err != nil {
+		return err
+	}
+	h := hs[group]
+	for _, x := range xs[1:] {
+		i := strings.IndexByte(x, '=')
+		if i < 0 {
+			return fmt.Errorf("invalid argument: %s", x)
+		}
+		key, value := strings.ToLower(x[:i]), strings.ToLower(x[i+1:])
+		switch key {
+		case "fg", "bg":
+			if value == "none" {
+				value = ""
+			} else if !validColor(value) {
+				return fmt.Errorf("invalid color: %s", x[i+1:])
+			}
+			if key == "fg" {
+				h.Foreground = value
+			} else {
+				h.Background = value
+			}
+		case "attr":
+			h.Attr = 0
+			if value == "none" {
+				continue
+			}
+			for _, name := range strings.Split(value, ",") {
+				j := indexOf(attrNames, name)
+				if j < 0 {
+					return fmt.Errorf("invalid attribute: %s", name)
+				}
+				h.Attr |= 1 << uint(j)
+			}
+		default:
+			return fmt.Errorf("invalid argument: %s", x)
+		}
+	}
+	hs[group] = h
+	return nil
+}
+
+// Show the highlight groups matching the group name.
+func (hs Highlights) Show(arg string) (string, error) {
+	groups := Groups
+	if arg = strings.TrimSpace(arg); arg != "" {
+		group, err := lookupGroup(arg)
+		if err != nil {
+			return "", err
+		}
+		groups = []string{group}
+	}
+	lines := make([]string, len(groups))
+	for i, group := range groups {
+		lines[i] = fmt.Sprintf("%-16s %s", group, hs[group])
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func lookupGroup(name string) (string, error) {
+	for _, group := range Groups {
+		if strings.EqualFold(group, name) {
+			return group, nil
+		}
+	}
+	return "", fmt.Errorf("unknown highlight group: %s", name)
+}
+
+func indexOf(xs []string, x string) int {
+	for i, y := range xs {
+		if x == y {
+			return i
+		}
+	}
+	return -1
+}
+
+// Load the color scheme. Each line of the color scheme is the arguments of
+// the highlight command optionally prefixed by highlight. Empty lines and
+// lines starting with " or # are ignored.
+func (hs Highlights) Load(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	for i := 1; s.Scan(); i++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '"' || line[0] == '#' {
+			continue
+		}
+		if xs := strings.SplitN(line, " ", 2); len(xs) == 2 &&
+			(xs[0] == "highlight" || xs[0] == "hi") {
+			line = xs[1]
+		}
+		if err := hs.Set(line); err != nil {
+			return fmt.Errorf("line %d: %s", i, err)
+		}
+	}
+	return s.Err()
+}
+
+// Default returns the highlights of the default color scheme.
+func Default() Highlights {
+	return defaultHighlights.Clone()
+}
+
+// ColorSchemes returns the names of the built-in color schemes.
+func ColorSchemes() []string {
+	names := make([]string, 0, len(colorSchemes))
+	for name := range colorSchemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ColorScheme returns the highlights of the built-in color scheme.
+func ColorScheme(name string) (Highlights, bool) {
+	scheme, ok := colorSchemes[name]
+	if !ok {
+		return nil, false
+	}
+	hs := Default()
+	for group, h := range scheme {
+		hs[group] = h
+	}
+	return hs, true
+}
+
+// The default color scheme colors the bytes by the categories like hexyl.
+var defaultHighlights = Highlights{
+	Offset:          {Foreground: "gray"},
+	CursorOffset:    {Attr: AttrBold},
+	Header:          {Attr: AttrUnderline},
+	Separator:       {},
+	HexByte:         {},
+	TextByte:        {},
+	NullByte:        {Foreground: "gray"},
+	FFByte:          {Foreground: "navy"},
+	AsciiPrintable:  {Foreground: "teal"},
+	AsciiWhitespace: {Foreground: "green"},
+	AsciiOther:      {Foreground: "purple"},
+	NonAscii:        {Foreground: "olive"},
+	Edited:          {Foreground: "red", Attr: AttrBold},
+	Cursor:          {Attr: AttrReverse},
+	CursorNC:        {Attr: AttrBold | AttrUnderline},
+	CursorLine:      {Background: "236"},
+	CursorColumn:    {Background: "236"},
+	Visual:          {Attr: AttrUnderline},
+	Search:          {Attr: AttrReverse},
+	ScrollBar:       {},
+	StatusLine:      {Attr: AttrReverse},
+	StatusLineNC:    {Attr: AttrReverse},
+	VertSplit:       {Attr: AttrReverse},
+	TabLine:         {Attr: AttrReverse},
+	TabLineSel:      {Attr: AttrBold},
+	ErrorMsg:        {Foreground: "red"},
+	InfoMsg:         {Foreground: "yellow"},
+	Pmenu:           {Attr: AttrReverse},
+	PmenuSel:        {Foreground: "gray", Attr: AttrReverse},
+}
+
+var colorSchemes = map[string]Highlights{
+	"default": {},
+	// the monochrome color scheme without the byte categories
+	"plain": {
+		Offset:          {},
+		NullByte:        {},
+		FFByte:          {},
+		AsciiPrintable:  {},
+		AsciiWhitespace: {},
+		AsciiOther:      {},
+		NonAscii:        {},
+		Edited:          {Foreground: "lightseagreen"},
+		CursorLine:      {},
+		CursorColumn:    {},
+	},
+}
@@ -0,0 +1,110 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestHighlightsSet(t *testing.T) {
+	hs := Default()
+	for _, testCase := range []struct {
+		arg      string
+		group    string
+		expected string
+		err      string
+	}{
+		{"Offset fg=red bg=#102030 attr=bold,underline", Offset, "fg=red bg=#102030 attr=bold,underline", ""},
+		{"offset bg=NONE attr=reverse", Offset, "fg=red attr=reverse", ""},
+		{"NullByte fg=242", NullByte, "fg=242", ""},
+		{"Cursor attr=none", Cursor, "cleared", ""},
+		{"clear Offset", Offset, "fg=gray", ""},
+		{"Unknown fg=red", "", "", "unknown highlight group: Unknown"},
+		{"Offset fg=unknown", "", "", "invalid color: unknown"},
+		{"Offset fg=256", "", "", "invalid color: 256"},
+		{"Offset attr=bold,italic", "", "", "invalid attribute: italic"},
+		{"Offset red", "", "", "invalid argument: red"},
+		{"clear", Cursor, "attr=reverse", ""},
+	} {
+		err := hs.Set(testCase.arg)
+		if testCase.err != "" {
+			if err == nil || err.Error() != testCase.err {
+				t.Errorf("Set(%q) should return error %q but got %v", testCase.arg, testCase.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Set(%q) should not return error but got %v", testCase.arg, err)
+		}
+		if got := hs[testCase.group].String(); got != testCase.expected {
+			t.Errorf("Set(%q) should set %s to %q but got %q", testCase.arg, testCase.group, testCase.expected, got)
+		}
+	}
+}
+
+func TestHighlightsShow(t *testing.T) {
+	hs := Default()
+	got, err := hs.Show("edited")
+	if expected := "Edited           fg=red attr=bold"; got != expected || err != nil {
+		t.Errorf("Show should return %q but got %q, %v", expected, got, err)
+	}
+	got, _ = hs.Show("")
+	if lines := strings.Split(got, "\n"); len(lines) != len(Groups) {
+		t.Errorf("Show should return %d lines but got %d lines", len(Groups), len(lines))
+	}
+}
+
+func TestHighlightsLoad(t *testing.T) {
+	hs := Default()
+	err := hs.Load(strings.NewReader(`" comment
+# comment
+highlight Offset fg=yellow
+
+hi Cursor fg=black bg=white
+NullByte fg=NONE attr=dim
+`))
+	if err != nil {
+		t.Errorf("Load should not return error but got %v", err)
+	}
+	for group, expected := range map[string]string{
+		Offset:   "fg=yellow",
+		Cursor:   "fg=black bg=white attr=reverse",
+		NullByte: "attr=dim",
+	} {
+		if got := hs[group].String(); got != expected {
+			t.Errorf("Load should set %s to %q but got %q", group, expected, got)
+		}
+	}
+	err = hs.Load(strings.NewReader("Offset fg=red\nOffset fg=foo\n"))
+	if expected := "line 2: invalid color: foo"; err == nil || err.Error() != expected {
+		t.Errorf("Load should return error %q but got %v", expected, err)
+	}
+}
+
+func TestHighlightStyle(t *testing.T) {
+	h := Highlight{Foreground: "red"}.Merge(Highlight{Background: "1", Attr: AttrBold})
+	fg, bg, attr := h.Style().Decompose()
+	if fg != tcell.ColorRed || bg != tcell.ColorMaroon || attr != tcell.AttrBold {
+		t.Errorf("Style should return red on maroon bold but got %v, %v, %v", fg, bg, attr)
+	}
+}
+
+func TestColorScheme(t *testing.T) {
+	if names := ColorSchemes(); strings.Join(names, ",") != "default,plain" {
+		t.Errorf("ColorSchemes should return the built-in color schemes but got %v", names)
+	}
+	hs, ok := ColorScheme("plain")
+	if !ok {
+		t.Fatalf("ColorScheme should return the plain color scheme")
+	}
+	if got := hs[AsciiPrintable].String(); got != "cleared" {
+		t.Errorf("plain color scheme should clear AsciiPrintable but got %q", got)
+	}
+	if got := hs[Cursor].String(); got != "attr=reverse" {
+		t.Errorf("plain color scheme should keep Cursor but got %q", got)
+	}
+	if _, ok := ColorScheme("unknown"); ok {
+		t.Errorf("ColorScheme should not return unknown color scheme")
+	}
+}
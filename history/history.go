@@ -1,6 +1,10 @@
 package history
 
-import "github.com/itchyny/bed/buffer"
+import (
+	"io"
+
+	"github.com/itchyny/bed/buffer"
+)
 
 // History manages the buffer history.
 type History struct {
@@ -54,3 +58,13 @@ func (h *History) Redo() (*buffer.Buffer, int64, int64, uint64) {
 	e := h.entries[h.index]
 	return e.buffer.Clone(), e.offset, e.cursor, e.tick
 }
+
+// Refers reports whether any buffer in the history reads from the reader.
+func (h *History) Refers(r io.ReaderAt) bool {
+	for _, e := range h.entries {
+		if e.buffer.Refers(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -9,6 +9,13 @@ import (
 // Key represents one keyboard stroke.
 type Key string
 
+func (k Key) rune() rune {
+	if rs := []rune(string(k)); len(rs) == 1 {
+		return rs[0]
+	}
+	return 0
+}
+
 type keyEvent struct {
 	keys  []Key
 	event event.Type
@@ -82,7 +89,7 @@ func (km *Manager) Press(k Key) event.Event {
 				return event.Event{Type: event.Nop}
 			case keysEq:
 				km.keys = nil
-				return event.Event{Type: ke.event, Count: count, Bang: ke.bang}
+				return event.Event{Type: ke.event, Count: count, Bang: ke.bang, Rune: k.rune()}
 			}
 		}
 	}
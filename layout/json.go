@@ -0,0 +1,93 @@
+package layout
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// The layout is encoded to JSON without the positions, which are
+// recalculated by Resize after decoding. The examples of the encoding;
+//   {"window":0,"active":true}
+//   {"horizontal":[{"window":0},{"window":1,"active":true}],"ratio":0.4}
+//   {"vertical":[{"window":0},{"window":1,"active":true}]}
+
+type jsonLayout struct {
+	Window     *int              `json:"window,omitempty"`
+	Active     bool              `json:"active,omitempty"`
+	Horizontal []json.RawMessage `json:"horizontal,omitempty"`
+	Vertical   []json.RawMessage `json:"vertical,omitempty"`
+	Ratio      float64           `json:"ratio,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l Window) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonLayout{Window: &l.Index, Active: l.Active})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l Horizontal) MarshalJSON() ([]byte, error) {
+	xs, err := marshalPair(l.Top, l.Bottom)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonLayout{Horizontal: xs, Ratio: l.ratio})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l Vertical) MarshalJSON() ([]byte, error) {
+	xs, err := marshalPair(l.Left, l.Right)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonLayout{Vertical: xs, Ratio: l.ratio})
+}
+
+func marshalPair(l1, l2 Layout) ([]json.RawMessage, error) {
+	x1, err := json.Marshal(l1)
+	if err != nil {
+		return nil, err
+	}
+	x2, err := json.Marshal(l2)
+	if err != nil {
+		return nil, err
+	}
+	return []json.RawMessage{x1, x2}, nil
+}
+
+// Unmarshal decodes the layout encoded by json.Marshal.
+func Unmarshal(data []byte) (Layout, error) {
+	var l jsonLayout
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, err
+	}
+	switch {
+	case l.Window != nil:
+		return Window{Index: *l.Window, Active: l.Active}, nil
+	case len(l.Horizontal) == 2:
+		l1, l2, err := unmarshalPair(l.Horizontal)
+		if err != nil {
+			return nil, err
+		}
+		return Horizontal{Top: l1, Bottom: l2, ratio: l.Ratio}, nil
+	case len(l.Vertical) == 2:
+		l1, l2, err := unmarshalPair(l.Vertical)
+		if err != nil {
+			return nil, err
+		}
+		return Vertical{Left: l1, Right: l2, ratio: l.Ratio}, nil
+	default:
+		return nil, errors.New("invalid layout")
+	}
+}
+
+func unmarshalPair(xs []json.RawMessage) (Layout, Layout, error) {
+	l1, err := Unmarshal(xs[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	l2, err := Unmarshal(xs[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	return l1, l2, nil
+}
@@ -0,0 +1,39 @@
+package layout
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLayoutJSON(t *testing.T) {
+	layout := NewLayout(0).SplitTop(1).SplitLeft(2).Resize(0, 0, 100, 40).SetHeight(10).Resize(0, 0, 100, 40)
+
+	got, err := json.Marshal(layout)
+	if err != nil {
+		t.Fatalf("err should be nil but got: %v", err)
+	}
+	expected := `{"horizontal":[{"vertical":[{"window":2,"active":true},{"window":1}]},{"window":0}],"ratio":0.25}`
+	if string(got) != expected {
+		t.Errorf("json should be %s but got %s", expected, got)
+	}
+
+	decoded, err := Unmarshal(got)
+	if err != nil {
+		t.Fatalf("err should be nil but got: %v", err)
+	}
+	if decoded = decoded.Resize(0, 0, 100, 40); !reflect.DeepEqual(decoded, layout) {
+		t.Errorf("layout should be %#v but got %#v", layout, decoded)
+	}
+
+	for _, data := range []string{
+		`{}`,
+		`{"horizontal":[{"window":0}]}`,
+		`{"vertical":[{"window":0},{}]}`,
+		`[]`,
+	} {
+		if _, err := Unmarshal([]byte(data)); err == nil {
+			t.Errorf("err should not be nil for %s", data)
+		}
+	}
+}
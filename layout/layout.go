@@ -22,6 +22,12 @@ type Layout interface {
 	ActiveWindow() Window
 	Lookup(func(Window) bool) Window
 	Close() Layout
+	SetHeight(int) Layout
+	SetWidth(int) Layout
+	Equalize() Layout
+	Rotate(bool) Layout
+	Exchange() Layout
+	Only() Layout
 }
 
 // Window holds the window index and it is active or not.
@@ -165,6 +171,36 @@ func (l Window) Close() Layout {
 	return l
 }
 
+// SetHeight sets the height of the active window.
+func (l Window) SetHeight(int) Layout {
+	return l
+}
+
+// SetWidth sets the width of the active window.
+func (l Window) SetWidth(int) Layout {
+	return l
+}
+
+// Equalize makes all the windows almost the same size.
+func (l Window) Equalize() Layout {
+	return l
+}
+
+// Rotate the windows in the same row or column.
+func (l Window) Rotate(bool) Layout {
+	return l
+}
+
+// Exchange the active window with the next one.
+func (l Window) Exchange() Layout {
+	return l
+}
+
+// Only closes all the windows except the active one.
+func (l Window) Only() Layout {
+	return NewLayout(l.ActiveWindow().Index)
+}
+
 // Horizontal holds two layout horizontally.
 type Horizontal struct {
 	Top    Layout
@@ -173,6 +209,7 @@ type Horizontal struct {
 	top    int
 	width  int
 	height int
+	ratio  float64
 }
 
 func (l Horizontal) isLayout() {}
@@ -195,6 +232,7 @@ func (l Horizontal) Replace(index int) Layout {
 		top:    l.top,
 		width:  l.width,
 		height: l.height,
+		ratio:  l.ratio,
 	}
 }
 
@@ -203,6 +241,9 @@ func (l Horizontal) Resize(left, top, width, height int) Layout {
 	_, h1 := l.Top.Count()
 	_, h2 := l.Bottom.Count()
 	topHeight := height * h1 / (h1 + h2)
+	if l.ratio > 0 {
+		topHeight = clamp(int(float64(height)*l.ratio+0.5), h1, height-h2)
+	}
 	return Horizontal{
 		Top:    l.Top.Resize(left, top, width, topHeight),
 		Bottom: l.Bottom.Resize(left, top+topHeight, width, height-topHeight),
@@ -210,6 +251,7 @@ func (l Horizontal) Resize(left, top, width, height int) Layout {
 		top:    top,
 		width:  width,
 		height: height,
+		ratio:  l.ratio,
 	}
 }
 
@@ -238,6 +280,7 @@ func (l Horizontal) SplitTop(index int) Layout {
 	return Horizontal{
 		Top:    l.Top.SplitTop(index),
 		Bottom: l.Bottom.SplitTop(index),
+		ratio:  l.ratio,
 	}
 }
 
@@ -246,6 +289,7 @@ func (l Horizontal) SplitBottom(index int) Layout {
 	return Horizontal{
 		Top:    l.Top.SplitBottom(index),
 		Bottom: l.Bottom.SplitBottom(index),
+		ratio:  l.ratio,
 	}
 }
 
@@ -254,6 +298,7 @@ func (l Horizontal) SplitLeft(index int) Layout {
 	return Horizontal{
 		Top:    l.Top.SplitLeft(index),
 		Bottom: l.Bottom.SplitLeft(index),
+		ratio:  l.ratio,
 	}
 }
 
@@ -262,6 +307,7 @@ func (l Horizontal) SplitRight(index int) Layout {
 	return Horizontal{
 		Top:    l.Top.SplitRight(index),
 		Bottom: l.Bottom.SplitRight(index),
+		ratio:  l.ratio,
 	}
 }
 
@@ -281,6 +327,7 @@ func (l Horizontal) Activate(i int) Layout {
 		top:    l.top,
 		width:  l.width,
 		height: l.height,
+		ratio:  l.ratio,
 	}
 }
 
@@ -293,6 +340,7 @@ func (l Horizontal) ActivateFirst() Layout {
 		top:    l.top,
 		width:  l.width,
 		height: l.height,
+		ratio:  l.ratio,
 	}
 }
 
@@ -329,9 +377,67 @@ func (l Horizontal) Close() Layout {
 	return Horizontal{
 		Top:    l.Top.Close(),
 		Bottom: l.Bottom.Close(),
+		ratio:  l.ratio,
 	}
 }
 
+// SetHeight sets the height of the active window.
+func (l Horizontal) SetHeight(height int) Layout {
+	_, h1 := l.Top.Count()
+	_, h2 := l.Bottom.Count()
+	var topHeight int
+	if l.Top.ActiveWindow().Index >= 0 {
+		l.Top = l.Top.SetHeight(height).Resize(
+			l.Top.LeftMargin(), l.Top.TopMargin(), l.Top.Width(), l.Top.Height())
+		topHeight = l.Top.Height() + height - l.Top.ActiveWindow().Height()
+	} else if l.Bottom.ActiveWindow().Index >= 0 {
+		l.Bottom = l.Bottom.SetHeight(height).Resize(
+			l.Bottom.LeftMargin(), l.Bottom.TopMargin(), l.Bottom.Width(), l.Bottom.Height())
+		topHeight = l.Top.Height() - height + l.Bottom.ActiveWindow().Height()
+	} else {
+		return l
+	}
+	if l.height > 0 {
+		l.ratio = float64(clamp(topHeight, h1, l.height-h2)) / float64(l.height)
+	}
+	return l
+}
+
+// SetWidth sets the width of the active window.
+func (l Horizontal) SetWidth(width int) Layout {
+	l.Top, l.Bottom = l.Top.SetWidth(width), l.Bottom.SetWidth(width)
+	return l
+}
+
+// Equalize makes all the windows almost the same size.
+func (l Horizontal) Equalize() Layout {
+	l.Top, l.Bottom, l.ratio = l.Top.Equalize(), l.Bottom.Equalize(), 0
+	return l
+}
+
+// Rotate the windows in the same row or column.
+func (l Horizontal) Rotate(downwards bool) Layout {
+	if xs, i := chain(l); i >= 0 {
+		return rotate(l, xs, downwards)
+	}
+	l.Top, l.Bottom = l.Top.Rotate(downwards), l.Bottom.Rotate(downwards)
+	return l
+}
+
+// Exchange the active window with the next one.
+func (l Horizontal) Exchange() Layout {
+	if xs, i := chain(l); i >= 0 {
+		return exchange(l, xs, i)
+	}
+	l.Top, l.Bottom = l.Top.Exchange(), l.Bottom.Exchange()
+	return l
+}
+
+// Only closes all the windows except the active one.
+func (l Horizontal) Only() Layout {
+	return NewLayout(l.ActiveWindow().Index)
+}
+
 // Vertical holds two layout vertically.
 type Vertical struct {
 	Left   Layout
@@ -340,6 +446,7 @@ type Vertical struct {
 	top    int
 	width  int
 	height int
+	ratio  float64
 }
 
 func (l Vertical) isLayout() {}
@@ -362,6 +469,7 @@ func (l Vertical) Replace(index int) Layout {
 		top:    l.top,
 		width:  l.width,
 		height: l.height,
+		ratio:  l.ratio,
 	}
 }
 
@@ -370,6 +478,9 @@ func (l Vertical) Resize(left, top, width, height int) Layout {
 	w1, _ := l.Left.Count()
 	w2, _ := l.Right.Count()
 	leftWidth := width * w1 / (w1 + w2)
+	if l.ratio > 0 {
+		leftWidth = clamp(int(float64(width)*l.ratio+0.5), 2*w1-1, width-2*w2)
+	}
 	return Vertical{
 		Left: l.Left.Resize(left, top, leftWidth, height),
 		Right: l.Right.Resize(
@@ -379,6 +490,7 @@ func (l Vertical) Resize(left, top, width, height int) Layout {
 		top:    top,
 		width:  width,
 		height: height,
+		ratio:  l.ratio,
 	}
 }
 
@@ -407,6 +519,7 @@ func (l Vertical) SplitTop(index int) Layout {
 	return Vertical{
 		Left:  l.Left.SplitTop(index),
 		Right: l.Right.SplitTop(index),
+		ratio: l.ratio,
 	}
 }
 
@@ -415,6 +528,7 @@ func (l Vertical) SplitBottom(index int) Layout {
 	return Vertical{
 		Left:  l.Left.SplitBottom(index),
 		Right: l.Right.SplitBottom(index),
+		ratio: l.ratio,
 	}
 }
 
@@ -423,6 +537,7 @@ func (l Vertical) SplitLeft(index int) Layout {
 	return Vertical{
 		Left:  l.Left.SplitLeft(index),
 		Right: l.Right.SplitLeft(index),
+		ratio: l.ratio,
 	}
 }
 
@@ -431,6 +546,7 @@ func (l Vertical) SplitRight(index int) Layout {
 	return Vertical{
 		Left:  l.Left.SplitRight(index),
 		Right: l.Right.SplitRight(index),
+		ratio: l.ratio,
 	}
 }
 
@@ -450,6 +566,7 @@ func (l Vertical) Activate(i int) Layout {
 		top:    l.top,
 		width:  l.width,
 		height: l.height,
+		ratio:  l.ratio,
 	}
 }
 
@@ -462,6 +579,7 @@ func (l Vertical) ActivateFirst() Layout {
 		top:    l.top,
 		width:  l.width,
 		height: l.height,
+		ratio:  l.ratio,
 	}
 }
 
@@ -498,5 +616,153 @@ func (l Vertical) Close() Layout {
 	return Vertical{
 		Left:  l.Left.Close(),
 		Right: l.Right.Close(),
+		ratio: l.ratio,
 	}
 }
+
+// SetHeight sets the height of the active window.
+func (l Vertical) SetHeight(height int) Layout {
+	l.Left, l.Right = l.Left.SetHeight(height), l.Right.SetHeight(height)
+	return l
+}
+
+// SetWidth sets the width of the active window.
+func (l Vertical) SetWidth(width int) Layout {
+	w1, _ := l.Left.Count()
+	w2, _ := l.Right.Count()
+	var leftWidth int
+	if l.Left.ActiveWindow().Index >= 0 {
+		l.Left = l.Left.SetWidth(width).Resize(
+			l.Left.LeftMargin(), l.Left.TopMargin(), l.Left.Width(), l.Left.Height())
+		leftWidth = l.Left.Width() + width - l.Left.ActiveWindow().Width()
+	} else if l.Right.ActiveWindow().Index >= 0 {
+		l.Right = l.Right.SetWidth(width).Resize(
+			l.Right.LeftMargin(), l.Right.TopMargin(), l.Right.Width(), l.Right.Height())
+		leftWidth = l.Left.Width() - width + l.Right.ActiveWindow().Width()
+	} else {
+		return l
+	}
+	if l.width > 0 {
+		l.ratio = float64(clamp(leftWidth, 2*w1-1, l.width-2*w2)) / float64(l.width)
+	}
+	return l
+}
+
+// Equalize makes all the windows almost the same size.
+func (l Vertical) Equalize() Layout {
+	l.Left, l.Right, l.ratio = l.Left.Equalize(), l.Right.Equalize(), 0
+	return l
+}
+
+// Rotate the windows in the same row or column.
+func (l Vertical) Rotate(downwards bool) Layout {
+	if xs, i := chain(l); i >= 0 {
+		return rotate(l, xs, downwards)
+	}
+	l.Left, l.Right = l.Left.Rotate(downwards), l.Right.Rotate(downwards)
+	return l
+}
+
+// Exchange the active window with the next one.
+func (l Vertical) Exchange() Layout {
+	if xs, i := chain(l); i >= 0 {
+		return exchange(l, xs, i)
+	}
+	l.Left, l.Right = l.Left.Exchange(), l.Right.Exchange()
+	return l
+}
+
+// Only closes all the windows except the active one.
+func (l Vertical) Only() Layout {
+	return NewLayout(l.ActiveWindow().Index)
+}
+
+// chain returns the layouts in the row or column of the split direction,
+// and the index of the active window if it is directly in the row or column.
+func chain(l Layout) ([]Layout, int) {
+	var xs []Layout
+	var collect func(Layout)
+	collect = func(m Layout) {
+		switch m := m.(type) {
+		case Horizontal:
+			if _, ok := l.(Horizontal); ok {
+				collect(m.Top)
+				collect(m.Bottom)
+				return
+			}
+		case Vertical:
+			if _, ok := l.(Vertical); ok {
+				collect(m.Left)
+				collect(m.Right)
+				return
+			}
+		}
+		xs = append(xs, m)
+	}
+	collect(l)
+	for i, x := range xs {
+		if w, ok := x.(Window); ok && w.Active {
+			return xs, i
+		}
+	}
+	return xs, -1
+}
+
+// rebuild replaces the layouts in the row or column in order.
+func rebuild(l Layout, xs []Layout) Layout {
+	var replace func(Layout) Layout
+	replace = func(m Layout) Layout {
+		switch m := m.(type) {
+		case Horizontal:
+			if _, ok := l.(Horizontal); ok {
+				m.Top = replace(m.Top)
+				m.Bottom = replace(m.Bottom)
+				return m
+			}
+		case Vertical:
+			if _, ok := l.(Vertical); ok {
+				m.Left = replace(m.Left)
+				m.Right = replace(m.Right)
+				return m
+			}
+		}
+		x := xs[0]
+		xs = xs[1:]
+		return x
+	}
+	return replace(l)
+}
+
+func rotate(l Layout, xs []Layout, downwards bool) Layout {
+	ys := make([]Layout, len(xs))
+	for i, x := range xs {
+		if downwards {
+			ys[(i+1)%len(xs)] = x
+		} else {
+			ys[(i+len(xs)-1)%len(xs)] = x
+		}
+	}
+	return rebuild(l, ys)
+}
+
+func exchange(l Layout, xs []Layout, i int) Layout {
+	j := i + 1
+	if j == len(xs) {
+		j = i - 1
+	}
+	if j < 0 {
+		return l
+	}
+	w1, ok1 := xs[i].(Window)
+	w2, ok2 := xs[j].(Window)
+	if !ok1 || !ok2 {
+		return l // cannot exchange with a split window
+	}
+	w1.Index, w2.Index = w2.Index, w1.Index
+	xs[i], xs[j] = w1, w2
+	return rebuild(l, xs)
+}
+
+func clamp(x, min, max int) int {
+	return mathutil.MaxInt(mathutil.MinInt(mathutil.MaxInt(x, min), max), 0)
+}
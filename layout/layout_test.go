@@ -305,3 +305,158 @@ func TestLayout(t *testing.T) {
 		t.Errorf("Height() should be %+v but layout %+v", 10, layout.Height())
 	}
 }
+
+func TestLayoutSetHeightWidth(t *testing.T) {
+	layout := NewLayout(0).SplitBottom(1).SplitRight(2).Resize(0, 0, 30, 20)
+
+	sizes := func(layout Layout) map[int][2]int {
+		m := make(map[int][2]int)
+		for i, w := range layout.Collect() {
+			m[i] = [2]int{w.Width(), w.Height()}
+		}
+		return m
+	}
+
+	for _, testCase := range []struct {
+		name     string
+		modifier func(Layout) Layout
+		expected map[int][2]int
+	}{
+		{"initial", func(l Layout) Layout { return l },
+			map[int][2]int{0: {30, 10}, 1: {15, 10}, 2: {14, 10}}},
+		{"SetHeight(14)", func(l Layout) Layout { return l.SetHeight(14) },
+			map[int][2]int{0: {30, 6}, 1: {15, 14}, 2: {14, 14}}},
+		{"SetWidth(5)", func(l Layout) Layout { return l.SetWidth(5) },
+			map[int][2]int{0: {30, 6}, 1: {24, 14}, 2: {5, 14}}},
+		{"SetHeight(100)", func(l Layout) Layout { return l.SetHeight(100) },
+			map[int][2]int{0: {30, 1}, 1: {24, 19}, 2: {5, 19}}},
+		{"SplitTop(3)", func(l Layout) Layout { return l.SplitTop(3) },
+			map[int][2]int{0: {30, 1}, 1: {24, 19}, 2: {5, 10}, 3: {5, 9}}},
+		{"SetHeight(15)", func(l Layout) Layout { return l.SetHeight(15) },
+			map[int][2]int{0: {30, 1}, 1: {24, 19}, 2: {5, 4}, 3: {5, 15}}},
+		{"SetWidth(0)", func(l Layout) Layout { return l.SetWidth(0) },
+			map[int][2]int{0: {30, 1}, 1: {28, 19}, 2: {1, 4}, 3: {1, 15}}},
+		{"Activate(0)", func(l Layout) Layout { return l.Activate(0).SetHeight(10) },
+			map[int][2]int{0: {30, 10}, 1: {28, 10}, 2: {1, 2}, 3: {1, 8}}},
+		{"Equalize", func(l Layout) Layout { return l.Equalize() },
+			map[int][2]int{0: {30, 6}, 1: {15, 14}, 2: {14, 7}, 3: {14, 7}}},
+	} {
+		layout = testCase.modifier(layout).Resize(0, 0, 30, 20)
+		if got := sizes(layout); !reflect.DeepEqual(got, testCase.expected) {
+			t.Errorf("%s: sizes should be %v but got %v", testCase.name, testCase.expected, got)
+		}
+	}
+
+	layout = layout.Activate(3).SetWidth(10).Resize(0, 0, 30, 20).Resize(0, 0, 60, 40)
+	expected := map[int][2]int{0: {60, 13}, 1: {38, 27}, 2: {21, 14}, 3: {21, 13}}
+	if got := sizes(layout); !reflect.DeepEqual(got, expected) {
+		t.Errorf("sizes should be %v but got %v", expected, got)
+	}
+}
+
+func TestLayoutRotate(t *testing.T) {
+	layout := NewLayout(0).SplitBottom(1).SplitBottom(2).Activate(1)
+
+	layout = layout.Rotate(true)
+	var expected Layout
+	expected = Horizontal{
+		Top: Window{Index: 2, Active: false},
+		Bottom: Horizontal{
+			Top:    Window{Index: 0, Active: false},
+			Bottom: Window{Index: 1, Active: true},
+		},
+	}
+	if !reflect.DeepEqual(layout, expected) {
+		t.Errorf("layout should be %#v but got %#v", expected, layout)
+	}
+
+	layout = layout.Rotate(false).Rotate(false)
+	expected = Horizontal{
+		Top: Window{Index: 1, Active: true},
+		Bottom: Horizontal{
+			Top:    Window{Index: 2, Active: false},
+			Bottom: Window{Index: 0, Active: false},
+		},
+	}
+	if !reflect.DeepEqual(layout, expected) {
+		t.Errorf("layout should be %#v but got %#v", expected, layout)
+	}
+
+	layout = Vertical{Left: layout, Right: Window{Index: 3}}.Rotate(true)
+	expected = Vertical{
+		Left: Horizontal{
+			Top: Window{Index: 0, Active: false},
+			Bottom: Horizontal{
+				Top:    Window{Index: 1, Active: true},
+				Bottom: Window{Index: 2, Active: false},
+			},
+		},
+		Right: Window{Index: 3, Active: false},
+	}
+	if !reflect.DeepEqual(layout, expected) {
+		t.Errorf("layout should be %#v but got %#v", expected, layout)
+	}
+
+	layout = layout.Activate(3).Rotate(true)
+	expected = Vertical{
+		Left: Window{Index: 3, Active: true},
+		Right: Horizontal{
+			Top: Window{Index: 0, Active: false},
+			Bottom: Horizontal{
+				Top:    Window{Index: 1, Active: false},
+				Bottom: Window{Index: 2, Active: false},
+			},
+		},
+	}
+	if !reflect.DeepEqual(layout, expected) {
+		t.Errorf("layout should be %#v but got %#v", expected, layout)
+	}
+}
+
+func TestLayoutExchange(t *testing.T) {
+	layout := NewLayout(0).SplitRight(1).SplitRight(2).SplitBottom(3).Activate(0)
+
+	layout = layout.Exchange()
+	var expected Layout
+	expected = Vertical{
+		Left: Window{Index: 1, Active: true},
+		Right: Vertical{
+			Left: Window{Index: 0, Active: false},
+			Right: Horizontal{
+				Top:    Window{Index: 2, Active: false},
+				Bottom: Window{Index: 3, Active: false},
+			},
+		},
+	}
+	if !reflect.DeepEqual(layout, expected) {
+		t.Errorf("layout should be %#v but got %#v", expected, layout)
+	}
+
+	layout = layout.Activate(0).Exchange()
+	if !reflect.DeepEqual(layout, layout.Activate(0)) {
+		t.Errorf("layout should not change but got %#v", layout)
+	}
+
+	layout = layout.Activate(3).Exchange()
+	expected = Vertical{
+		Left: Window{Index: 1, Active: false},
+		Right: Vertical{
+			Left: Window{Index: 0, Active: false},
+			Right: Horizontal{
+				Top:    Window{Index: 3, Active: false},
+				Bottom: Window{Index: 2, Active: true},
+			},
+		},
+	}
+	if !reflect.DeepEqual(layout, expected) {
+		t.Errorf("layout should be %#v but got %#v", expected, layout)
+	}
+}
+
+func TestLayoutOnly(t *testing.T) {
+	layout := NewLayout(0).SplitRight(1).SplitBottom(2).Activate(1).Only()
+	expected := Window{Index: 1, Active: true}
+	if !reflect.DeepEqual(layout, expected) {
+		t.Errorf("layout should be %#v but got %#v", expected, layout)
+	}
+}
@@ -0,0 +1,217 @@
+package option
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/itchyny/bed/charset"
+)
+
+// Options holds the values of the options.
+type Options struct {
+	ChecksumFix  bool
+	ColorBytes   bool
+	Crosshair    bool
+	Encoding     string
+	IncSearch    bool
+	OffsetBase   string
+	OffsetOrigin int64
+	WrapScan     bool
+}
+
+// option defines the name and the value of an option. The value is either
+// boolean, string or number, and the string value is one of the values.
+type option struct {
+	name    string
+	abbr    string
+	boolean func(*Options) *bool
+	str     func(*Options) *string
+	values  []string
+	number  func(*Options) *int64
+}
+
+var options = []option{
+	{name: "checksumfix", abbr: "cf", boolean: func(o *Options) *bool { return &o.ChecksumFix }},
+	{name: "colorbytes", abbr: "cb", boolean: func(o *Options) *bool { return &o.ColorBytes }},
+	{name: "crosshair", abbr: "ch", boolean: func(o *Options) *bool { return &o.Crosshair }},
+	{name: "encoding", abbr: "enc", str: func(o *Options) *string { return &o.Encoding }, values: charset.Names},
+	{name: "incsearch", abbr: "is", boolean: func(o *Options) *bool { return &o.IncSearch }},
+	{name: "offsetbase", abbr: "ob", str: func(o *Options) *string { return &o.OffsetBase }, values: []string{"hex", "dec", "oct", "none"}},
+	{name: "offsetorigin", abbr: "oo", number: func(o *Options) *int64 { return &o.OffsetOrigin }},
+	{name: "wrapscan", abbr: "ws", boolean: func(o *Options) *bool { return &o.WrapScan }},
+}
+
+// Default returns the default values of the options.
+func Default() Options {
+	return Options{
+		ColorBytes: true,
+		Crosshair:  true,
+		Encoding:   "utf-8",
+		OffsetBase: "hex",
+		WrapScan:   true,
+	}
+}
+
+// Names returns the names of the options.
+func Names() []string {
+	names := make([]string, len(options))
+	for i, o := range options {
+		names[i] = o.name
+	}
+	return names
+}
+
+// Values returns the values of the string option for completion.
+func Values(name string) []string {
+	if o, ok := lookupOption(name); ok {
+		return o.values
+	}
+	return nil
+}
+
+// Changed returns the arguments of Set to restore the options changed from
+// the default values.
+func (opts *Options) Changed() []string {
+	var args []string
+	defaults := Default()
+	for _, o := range options {
+		switch {
+		case o.boolean != nil && *o.boolean(opts) != *o.boolean(&defaults):
+			if *o.boolean(opts) {
+				args = append(args, o.name)
+			} else {
+				args = append(args, "no"+o.name)
+			}
+		case o.str != nil && *o.str(opts) != *o.str(&defaults):
+			args = append(args, o.name+"="+*o.str(opts))
+		case o.number != nil && *o.number(opts) != *o.number(&defaults):
+			args = append(args, fmt.Sprintf("%s=0x%x", o.name, *o.number(opts)))
+		}
+	}
+	return args
+}
+
+func lookupOption(name string) (option, bool) {
+	for _, o := range options {
+		if o.name == name || o.abbr == name {
+			return o, true
+		}
+	}
+	return option{}, false
+}
+
+// Set sets the options and returns the message to show. The arguments are
+// {option} or no{option} to set or reset, inv{option} or {option}! to
+// toggle, {option}={value} to set the string option, {option}? to show
+// the value and {option}& to reset to the default. Shows all the options
+// without arguments.
+func (opts *Options) Set(arg string) (string, error) {
+	args := strings.Fields(arg)
+	if len(args) == 0 {
+		args = Names()
+		for i := range args {
+			args[i] += "?"
+		}
+	}
+	var msgs []string
+	for _, arg := range args {
+		msg, err := opts.set(arg)
+		if err != nil {
+			return "", err
+		}
+		if msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	return strings.Join(msgs, "\n"), nil
+}
+
+func (opts *Options) set(arg string) (string, error) {
+	if i := strings.IndexByte(arg, '='); i >= 0 {
+		o, ok := lookupOption(arg[:i])
+		if !ok {
+			return "", fmt.Errorf("unknown option: %s", arg[:i])
+		}
+		value := arg[i+1:]
+		switch {
+		case o.str != nil && contains(o.values, value):
+			*o.str(opts) = value
+		case o.number != nil:
+			n, err := strconv.ParseInt(value, 0, 64)
+			if err != nil || n < 0 {
+				return "", fmt.Errorf("invalid argument: %s", arg)
+			}
+			*o.number(opts) = n
+		default:
+			return "", fmt.Errorf("invalid argument: %s", arg)
+		}
+		return "", nil
+	}
+	name, suffix := arg, ""
+	if strings.HasSuffix(name, "?") || strings.HasSuffix(name, "!") || strings.HasSuffix(name, "&") {
+		name, suffix = name[:len(name)-1], name[len(name)-1:]
+	}
+	o, ok := lookupOption(name)
+	var value, toggle bool
+	if ok {
+		value = true
+	} else if o, ok = lookupOption(strings.TrimPrefix(name, "no")); ok && strings.HasPrefix(name, "no") {
+		value = false
+	} else if o, ok = lookupOption(strings.TrimPrefix(name, "inv")); ok && strings.HasPrefix(name, "inv") {
+		toggle = true
+	} else {
+		return "", fmt.Errorf("unknown option: %s", arg)
+	}
+	if suffix != "" && name != o.name && name != o.abbr {
+		return "", fmt.Errorf("invalid argument: %s", arg)
+	}
+	if o.boolean == nil {
+		return opts.setValue(o, arg, name, suffix)
+	}
+	p := o.boolean(opts)
+	switch {
+	case suffix == "?":
+		if *p {
+			return "  " + o.name, nil
+		}
+		return "no" + o.name, nil
+	case suffix == "&":
+		defaults := Default()
+		*p = *o.boolean(&defaults)
+	case suffix == "!" || toggle:
+		*p = !*p
+	default:
+		*p = value
+	}
+	return "", nil
+}
+
+// setValue shows or resets the value of the string or number option.
+func (opts *Options) setValue(o option, arg, name, suffix string) (string, error) {
+	if name != o.name && name != o.abbr || suffix == "!" {
+		return "", fmt.Errorf("invalid argument: %s", arg)
+	}
+	defaults := Default()
+	if o.str != nil {
+		if suffix == "&" {
+			*o.str(opts) = *o.str(&defaults)
+			return "", nil
+		}
+		return "  " + o.name + "=" + *o.str(opts), nil
+	}
+	if suffix == "&" {
+		*o.number(opts) = *o.number(&defaults)
+		return "", nil
+	}
+	return fmt.Sprintf("  %s=0x%x", o.name, *o.number(opts)), nil
+}
+
+func contains(xs []string, x string) bool {
+	for _, y := range xs {
+		if x == y {
+			return true
+		}
+	}
+	return false
+}
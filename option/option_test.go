@@ -0,0 +1,80 @@
+package option
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptionsSet(t *testing.T) {
+	opts := Default()
+	for _, testCase := range []struct {
+		arg      string
+		expected Options
+		msg      string
+		err      string
+	}{
+		{"nocolorbytes", Options{ColorBytes: false, Crosshair: true, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", ""},
+		{"colorbytes? ch?", Options{ColorBytes: false, Crosshair: true, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "nocolorbytes\n  crosshair", ""},
+		{"invch cb", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", ""},
+		{"crosshair!", Options{ColorBytes: true, Crosshair: true, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", ""},
+		{"nocb noch", Options{ColorBytes: false, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", ""},
+		{"cb&", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", ""},
+		{"", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "nochecksumfix\n  colorbytes\nnocrosshair\n  encoding=utf-8\nnoincsearch\n  offsetbase=hex\n  offsetorigin=0x0\n  wrapscan", ""},
+		{"unknown", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", "unknown option: unknown"},
+		{"nocb?", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", "invalid argument: nocb?"},
+		{"encoding=ebcdic", Options{ColorBytes: true, Crosshair: false, Encoding: "ebcdic", OffsetBase: "hex", WrapScan: true}, "", ""},
+		{"enc enc?", Options{ColorBytes: true, Crosshair: false, Encoding: "ebcdic", OffsetBase: "hex", WrapScan: true}, "  encoding=ebcdic\n  encoding=ebcdic", ""},
+		{"enc=utf-16le cb", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-16le", OffsetBase: "hex", WrapScan: true}, "", ""},
+		{"enc&", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", ""},
+		{"enc=ascii", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", "invalid argument: enc=ascii"},
+		{"cb=utf-8", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", "invalid argument: cb=utf-8"},
+		{"noenc", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", "invalid argument: noenc"},
+		{"ob=dec oo=0x1000", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "dec", OffsetOrigin: 0x1000, WrapScan: true}, "", ""},
+		{"offsetorigin? oo=4096 oo", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "dec", OffsetOrigin: 4096, WrapScan: true}, "  offsetorigin=0x1000\n  offsetorigin=0x1000", ""},
+		{"oo& ob&", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", ""},
+		{"oo=-1", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", "invalid argument: oo=-1"},
+		{"oo=foo", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "hex", WrapScan: true}, "", "invalid argument: oo=foo"},
+		{"ob=none", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "none", WrapScan: true}, "", ""},
+		{"oo!", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", OffsetBase: "none", WrapScan: true}, "", "invalid argument: oo!"},
+		{"is", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", IncSearch: true, OffsetBase: "none", WrapScan: true}, "", ""},
+		{"nows", Options{ColorBytes: true, Crosshair: false, Encoding: "utf-8", IncSearch: true, OffsetBase: "none"}, "", ""},
+		{"cf cf?", Options{ChecksumFix: true, ColorBytes: true, Crosshair: false, Encoding: "utf-8", IncSearch: true, OffsetBase: "none"}, "  checksumfix", ""},
+	} {
+		msg, err := opts.Set(testCase.arg)
+		if testCase.err != "" {
+			if err == nil || err.Error() != testCase.err {
+				t.Errorf("Set(%q) should return error %q but got %v", testCase.arg, testCase.err, err)
+			}
+		} else if err != nil {
+			t.Errorf("Set(%q) should not return error but got %v", testCase.arg, err)
+		}
+		if msg != testCase.msg {
+			t.Errorf("Set(%q) should return message %q but got %q", testCase.arg, testCase.msg, msg)
+		}
+		if opts != testCase.expected {
+			t.Errorf("Set(%q) should set options to %+v but got %+v", testCase.arg, testCase.expected, opts)
+		}
+	}
+}
+
+func TestOptionsChanged(t *testing.T) {
+	opts := Default()
+	if args := opts.Changed(); len(args) != 0 {
+		t.Errorf("Changed should return no arguments for the defaults but got %v", args)
+	}
+	if _, err := opts.Set("nocb is enc=latin1 oo=0x8000"); err != nil {
+		t.Fatal(err)
+	}
+	args := opts.Changed()
+	if expected := []string{"nocolorbytes", "encoding=latin1", "incsearch", "offsetorigin=0x8000"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("Changed should return %v but got %v", expected, args)
+	}
+	restored := Default()
+	if _, err := restored.Set(strings.Join(args, " ")); err != nil {
+		t.Fatal(err)
+	}
+	if restored != opts {
+		t.Errorf("options should be restored to %+v but got %+v", opts, restored)
+	}
+}
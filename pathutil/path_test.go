@@ -0,0 +1,31 @@
+package pathutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHomedirExpand(t *testing.T) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, testCase := range []struct {
+		path, expected string
+	}{
+		{"", ""},
+		{"foo/bar", "foo/bar"},
+		{"/foo/~", "/foo/~"},
+		{"~", homeDir},
+		{"~/foo", filepath.Join(homeDir, "foo")},
+	} {
+		got, err := HomedirExpand(testCase.path)
+		if err != nil {
+			t.Errorf("HomedirExpand(%q) should not return error but got %v", testCase.path, err)
+		}
+		if got != testCase.expected {
+			t.Errorf("HomedirExpand(%q) should be %q but got %q", testCase.path, testCase.expected, got)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package searcher
+
+import "bytes"
+
+// horspoolMinSize is the minimum size of the target to use the
+// Boyer-Moore-Horspool algorithm. The shorter targets are searched by
+// bytes.Index, which is fast with the assembly implementations.
+const horspoolMinSize = 64
+
+// matcher finds the target in the bytes. The pattern is decoded once
+// when the matcher is created, and the chunks are lowered on matching
+// if the pattern ignores the case.
+type matcher struct {
+	target     []byte
+	ignoreCase bool
+	skip       *[256]int
+	rskip      *[256]int
+}
+
+func newMatcher(pattern string) (*matcher, error) {
+	target, ignoreCase, err := DecodeSearchPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	// the chunks are not lowered if the target has no letters
+	m := &matcher{target: target, ignoreCase: ignoreCase && bytes.IndexFunc(target, isLower) >= 0}
+	if n := len(target); n >= horspoolMinSize {
+		m.skip, m.rskip = new([256]int), new([256]int)
+		for i := range m.skip {
+			m.skip[i], m.rskip[i] = n, n
+		}
+		for i := 0; i < n-1; i++ {
+			m.skip[target[i]] = n - 1 - i
+		}
+		for i := n - 1; i > 0; i-- {
+			m.rskip[target[i]] = i
+		}
+	}
+	return m, nil
+}
+
+func isLower(r rune) bool {
+	return 'a' <= r && r <= 'z'
+}
+
+// index returns the first index of the target in the bytes, or -1.
+func (m *matcher) index(bs []byte) int {
+	if m.skip == nil {
+		return bytes.Index(bs, m.target)
+	}
+	n := len(m.target)
+	for i := 0; i+n <= len(bs); i += m.skip[bs[i+n-1]] {
+		if bs[i+n-1] == m.target[n-1] && bytes.Equal(bs[i:i+n-1], m.target[:n-1]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastIndex returns the last index of the target in the bytes, or -1.
+func (m *matcher) lastIndex(bs []byte) int {
+	if m.rskip == nil {
+		// repeats bytes.Index because bytes.LastIndex is not optimized
+		last := -1
+		for i := 0; ; {
+			j := bytes.Index(bs[i:], m.target)
+			if j < 0 {
+				return last
+			}
+			last = i + j
+			i = last + 1
+		}
+	}
+	n := len(m.target)
+	for i := len(bs) - n; i >= 0; i -= m.rskip[bs[i]] {
+		if bs[i] == m.target[0] && bytes.Equal(bs[i+1:i+n], m.target[1:]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexAll returns all the indices of the target in the bytes.
+// The matches can overlap each other.
+func (m *matcher) indexAll(bs []byte) []int {
+	var indices []int
+	for i := 0; ; {
+		j := m.index(bs[i:])
+		if j < 0 {
+			return indices
+		}
+		indices = append(indices, i+j)
+		i += j + 1
+	}
+}
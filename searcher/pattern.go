@@ -2,9 +2,118 @@ package searcher
 
 import (
 	"errors"
+	"strconv"
 	"unicode/utf8"
 )
 
+// DecodePattern decodes the pattern to the bytes. The pattern is a hex
+// literal (0x...), a binary literal (0b...) or a string with escapes.
+func DecodePattern(pattern string) ([]byte, error) {
+	return patternToTarget([]byte(pattern))
+}
+
+// DecodeSearchPattern decodes the search pattern to the bytes. The pattern
+// with \c ignores the case of the ASCII letters, and the bytes are lowered.
+func DecodeSearchPattern(pattern string) ([]byte, bool, error) {
+	pattern, ignoreCase := splitCaseFlag(pattern)
+	target, err := patternToTarget([]byte(pattern))
+	if err != nil {
+		return nil, false, err
+	}
+	if ignoreCase {
+		toLower(target)
+	}
+	return target, ignoreCase, nil
+}
+
+// splitCaseFlag removes \c and \C from the pattern,
+// and reports whether the pattern has \c.
+func splitCaseFlag(pattern string) (string, bool) {
+	var escape, ignoreCase bool
+	bs := make([]byte, 0, len(pattern))
+	for i := 0; i < len(pattern); i++ {
+		b := pattern[i]
+		if escape {
+			escape = false
+			if b == 'c' || b == 'C' {
+				ignoreCase = ignoreCase || b == 'c'
+				continue
+			}
+			bs = append(bs, '\\')
+		} else if b == '\\' {
+			escape = true
+			continue
+		}
+		bs = append(bs, b)
+	}
+	if escape {
+		bs = append(bs, '\\')
+	}
+	return string(bs), ignoreCase
+}
+
+func toLower(bs []byte) {
+	for i, b := range bs {
+		if 'A' <= b && b <= 'Z' {
+			bs[i] = b + 'a' - 'A'
+		}
+	}
+}
+
+// Offset is the offset of the cursor from the match; from the end of the
+// match if End is true, and from the start otherwise.
+type Offset struct {
+	End bool
+	N   int64
+}
+
+// SplitOffset splits the search string to the pattern and the offset
+// following the unescaped separator. The offset is e for the end of the
+// match, s or b for the start, optionally followed by [+-]N. The offset
+// of only [+-]N is from the start.
+func SplitOffset(str string, sep rune) (string, Offset, error) {
+	var escape bool
+	for i, c := range str {
+		if escape {
+			escape = false
+		} else if c == '\\' {
+			escape = true
+		} else if c == sep {
+			offset, err := parseOffset(str[i+1:])
+			return str[:i], offset, err
+		}
+	}
+	return str, Offset{}, nil
+}
+
+func parseOffset(str string) (Offset, error) {
+	var offset Offset
+	s := str
+	if s != "" {
+		switch s[0] {
+		case 'e':
+			offset.End = true
+			s = s[1:]
+		case 's', 'b':
+			s = s[1:]
+		}
+	}
+	switch s {
+	case "":
+	case "+":
+		offset.N = 1
+	case "-":
+		offset.N = -1
+	default:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return Offset{}, errors.New("invalid search offset: " + str)
+		}
+		offset.N = n
+	}
+	return offset, nil
+}
+
 func patternToTarget(pattern []byte) ([]byte, error) {
 	if len(pattern) > 3 && pattern[0] == '0' {
 		switch pattern[1] {
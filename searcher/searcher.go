@@ -1,142 +1,264 @@
 package searcher
 
 import (
-	"bytes"
 	"errors"
 	"io"
+	"math"
+	"runtime"
 	"sync"
 	"time"
 
 	"github.com/itchyny/bed/mathutil"
 )
 
-const loadSize = 1024 * 1024
+const (
+	loadSize         = 1024 * 1024
+	progressInterval = 100 * time.Millisecond
+)
 
 // Searcher represents a searcher.
 type Searcher struct {
-	r       io.ReaderAt
-	bytes   []byte
-	loopCh  chan struct{}
-	cursor  int64
-	pattern string
-	mu      *sync.Mutex
+	r      io.ReaderAt
+	quitCh chan struct{}
+	mu     *sync.Mutex
 }
 
 // NewSearcher creates a new searcher.
 func NewSearcher(r io.ReaderAt) *Searcher {
-	return &Searcher{r: r, bytes: make([]byte, loadSize), mu: new(sync.Mutex)}
+	return &Searcher{r: r, mu: new(sync.Mutex)}
 }
 
+// Progress is the number of the bytes searched so far. It is sent to the
+// channel of the search at intervals while the search is running.
+type Progress int64
+
 type errNotFound string
 
 func (err errNotFound) Error() string {
 	return "pattern not found: " + string(err)
 }
 
-// Search the pattern.
+// IsNotFound reports whether the error is that the pattern is not found.
+func IsNotFound(err error) bool {
+	_, ok := err.(errNotFound)
+	return ok
+}
+
+// Search the pattern. The channel receives the progress of the search and
+// then the offset of the match or an error, and it is closed at the end.
 func (s *Searcher) Search(cursor int64, pattern string, forward bool) <-chan interface{} {
+	limit := int64(math.MaxInt64)
+	if !forward {
+		limit = 0
+	}
+	return s.SearchUntil(cursor, limit, pattern, forward)
+}
+
+// SearchUntil searches the pattern like Search, but stops at the limit. The
+// match starts at or before the limit in the forward search, and at or after
+// the limit in the backward search.
+func (s *Searcher) SearchUntil(cursor, limit int64, pattern string, forward bool) <-chan interface{} {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.cursor, s.pattern = cursor, pattern
-	ch := make(chan interface{})
-	if forward {
-		s.loop(s.forward, ch)
-	} else {
-		s.loop(s.backward, ch)
+	if s.quitCh != nil {
+		close(s.quitCh)
 	}
+	quitCh := make(chan struct{})
+	s.quitCh = quitCh
+	ch := make(chan interface{})
+	go func() {
+		defer close(ch)
+		send := func(x interface{}) {
+			select {
+			case ch <- x:
+			case <-quitCh:
+			}
+		}
+		m, err := newMatcher(pattern)
+		if err != nil {
+			send(err)
+			return
+		}
+		start := cursor + 1
+		if !forward {
+			start = cursor
+		}
+		var result interface{} = errNotFound(pattern)
+		var searched int64
+		last := time.Now()
+		if err := scan(s.r, m, start, forward, false, quitCh, func(c *chunkResult) bool {
+			if len(c.indices) > 0 {
+				if x := c.base + int64(c.indices[0]); forward && x <= limit || !forward && x >= limit {
+					result = x
+				}
+				return false
+			}
+			if forward && c.base+int64(c.size) > limit || !forward && c.base <= limit {
+				return false
+			}
+			searched += int64(c.size)
+			if now := time.Now(); now.Sub(last) >= progressInterval {
+				send(Progress(searched))
+				last = now
+			}
+			return true
+		}); err != nil {
+			result = err
+		}
+		select {
+		case <-quitCh:
+		default:
+			send(result)
+		}
+	}()
 	return ch
 }
 
-func (s *Searcher) forward() (int64, error) {
+// Abort the searching.
+func (s *Searcher) Abort() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	target, err := patternToTarget([]byte(s.pattern))
-	if err != nil {
-		return -1, err
-	}
-	base := s.cursor + 1
-	n, err := s.r.ReadAt(s.bytes, base)
-	if err != nil && err != io.EOF {
-		return -1, err
-	}
-	if n == 0 {
-		return -1, errNotFound(s.pattern)
-	}
-	if err == io.EOF {
-		s.cursor += int64(n)
-	} else {
-		s.cursor += int64(n - len(target) + 1)
-	}
-	i := bytes.Index(s.bytes[:n], target)
-	if i >= 0 {
-		return base + int64(i), nil
+	if s.quitCh != nil {
+		close(s.quitCh)
+		s.quitCh = nil
+		return errors.New("search is aborted")
 	}
-	return -1, nil
+	return nil
 }
 
-func (s *Searcher) backward() (int64, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	target, err := patternToTarget([]byte(s.pattern))
+// FindAll finds all the offsets of the pattern from the head of the reader,
+// and calls the function with the offsets found in each chunk. The matches
+// can overlap each other like the repeated searches. It stops when the quit
+// channel is closed or the function returns false.
+func FindAll(r io.ReaderAt, pattern string, quit <-chan struct{}, f func([]int64) bool) error {
+	m, err := newMatcher(pattern)
 	if err != nil {
-		return -1, err
-	}
-	base := mathutil.MaxInt64(0, s.cursor-int64(loadSize))
-	size := int(mathutil.MinInt64(int64(loadSize), s.cursor))
-	n, err := s.r.ReadAt(s.bytes[:size], base)
-	if err != nil && err != io.EOF {
-		return -1, err
+		return err
 	}
-	if n == 0 {
-		return -1, errNotFound(s.pattern)
-	}
-	if s.cursor == int64(n) {
-		s.cursor = 0
-	} else {
-		s.cursor = base + int64(len(target)-1)
-	}
-	i := bytes.LastIndex(s.bytes[:n], target)
-	if i >= 0 {
-		return base + int64(i), nil
-	}
-	return -1, nil
+	return scan(r, m, 0, true, true, quit, func(c *chunkResult) bool {
+		if len(c.indices) == 0 {
+			return true
+		}
+		offsets := make([]int64, len(c.indices))
+		for i, j := range c.indices {
+			offsets[i] = c.base + int64(j)
+		}
+		return f(offsets)
+	})
 }
 
-func (s *Searcher) loop(f func() (int64, error), ch chan<- interface{}) {
-	if s.loopCh != nil {
-		close(s.loopCh)
+// chunkResult is the result of scanning a chunk. The indices are the
+// positions of the matches starting in the chunk, and last reports
+// whether the chunk reaches the end of the reader.
+type chunkResult struct {
+	base    int64
+	size    int
+	indices []int
+	last    bool
+	err     error
+}
+
+// scan reads the chunks from the start in the direction of the search, and
+// scans them in parallel with read-ahead. The start is the first position
+// of the forward search, and the end of the positions (exclusive) of the
+// backward search. The results are passed to the function in the order of
+// the chunks, until it returns false or the quit channel is closed.
+func scan(
+	r io.ReaderAt, m *matcher, start int64, forward, all bool,
+	quit <-chan struct{}, f func(*chunkResult) bool,
+) error {
+	if len(m.target) == 0 {
+		return errors.New("empty pattern")
 	}
-	loopCh := make(chan struct{})
-	s.loopCh = loopCh
+	workers := runtime.NumCPU()
+	resultChs := make(chan chan *chunkResult, workers)
+	done := make(chan struct{})
+	defer close(done)
 	go func() {
-		defer close(ch)
-		for {
-			select {
-			case <-loopCh:
-				return
-			case <-time.After(10 * time.Millisecond):
-				idx, err := f()
-				if err != nil {
-					ch <- err
-					return
-				}
-				if idx >= 0 {
-					ch <- idx
+		defer close(resultChs)
+		for k := int64(0); ; k++ {
+			base, size := start+k*loadSize, loadSize
+			if !forward {
+				end := start - k*loadSize
+				if end <= 0 {
 					return
 				}
+				base = mathutil.MaxInt64(0, end-loadSize)
+				size = int(end - base)
 			}
+			ch := make(chan *chunkResult, 1)
+			select {
+			case resultChs <- ch:
+			case <-done:
+				return
+			case <-quit:
+				return
+			}
+			go func() {
+				ch <- scanChunk(r, m, base, size, forward, all)
+			}()
 		}
 	}()
+	for ch := range resultChs {
+		var c *chunkResult
+		select {
+		case c = <-ch:
+		case <-quit:
+		}
+		select {
+		case <-quit:
+			return errors.New("search is aborted")
+		default:
+		}
+		if c.err != nil {
+			return c.err
+		}
+		if !f(c) || forward && c.last || !forward && c.base == 0 {
+			return nil
+		}
+	}
+	select {
+	case <-quit:
+		return errors.New("search is aborted")
+	default:
+		return nil
+	}
 }
 
-// Abort the searching.
-func (s *Searcher) Abort() error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if s.loopCh != nil {
-		close(s.loopCh)
-		s.loopCh = nil
-		return errors.New("search is aborted")
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new([]byte) },
+}
+
+// scanChunk reads the chunk and the following bytes shorter than the target,
+// so that the matches starting in the chunk are found.
+func scanChunk(r io.ReaderAt, m *matcher, base int64, size int, forward, all bool) *chunkResult {
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
+	n := size + len(m.target) - 1
+	if cap(*buf) < n {
+		*buf = make([]byte, n)
 	}
-	return nil
+	bs := (*buf)[:n]
+	k, err := r.ReadAt(bs, base)
+	if err != nil && err != io.EOF {
+		return &chunkResult{err: err}
+	}
+	c := &chunkResult{base: base, size: mathutil.MinInt(k, size), last: k < n}
+	bs = bs[:k]
+	if m.ignoreCase {
+		toLower(bs)
+	}
+	switch {
+	case all:
+		c.indices = m.indexAll(bs)
+	case forward:
+		if i := m.index(bs); i >= 0 {
+			c.indices = []int{i}
+		}
+	default:
+		if i := m.lastIndex(bs); i >= 0 {
+			c.indices = []int{i}
+		}
+	}
+	return c
 }
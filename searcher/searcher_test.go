@@ -1,8 +1,14 @@
 package searcher
 
 import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/itchyny/bed/mathutil"
 )
 
 func TestSearcher(t *testing.T) {
@@ -159,6 +165,38 @@ func TestSearcher(t *testing.T) {
 			forward:  true,
 			expected: 4,
 		},
+		{
+			name:     "search ignoring case",
+			str:      "abcABCdef",
+			cursor:   1,
+			pattern:  `\cAbC`,
+			forward:  true,
+			expected: 3,
+		},
+		{
+			name:     "search ignoring case backward",
+			str:      "xyzXYZ",
+			cursor:   5,
+			pattern:  `Xy\c`,
+			forward:  false,
+			expected: 3,
+		},
+		{
+			name:     "search hex literal ignoring case",
+			str:      "xyzXYZ",
+			cursor:   0,
+			pattern:  `0x5a\c`,
+			forward:  true,
+			expected: 2,
+		},
+		{
+			name:    "search matching case",
+			str:     "abcABC",
+			cursor:  0,
+			pattern: `\CAbC`,
+			forward: true,
+			err:     errNotFound(`\CAbC`),
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -182,3 +220,362 @@ func TestSearcher(t *testing.T) {
 		})
 	}
 }
+
+func TestSearcherSearchUntil(t *testing.T) {
+	str := "abc" + strings.Repeat("x", 3*loadSize) + "abc"
+	n := int64(len(str))
+	for _, testCase := range []struct {
+		cursor, limit int64
+		forward       bool
+		expected      int64
+	}{
+		{-1, 2, true, 0},
+		{0, 0, true, -1},
+		{0, 100, true, -1},
+		{0, n - 4, true, -1},
+		{0, n - 3, true, n - 3},
+		{n, n - 3, false, n - 3},
+		{n, n - 2, false, -1},
+		{n - 3, 1, false, -1},
+		{n - 3, 0, false, 0},
+	} {
+		s := NewSearcher(strings.NewReader(str))
+		var got interface{}
+		for x := range s.SearchUntil(testCase.cursor, testCase.limit, "abc", testCase.forward) {
+			if _, ok := x.(Progress); !ok {
+				got = x
+			}
+		}
+		if testCase.expected < 0 {
+			if err, ok := got.(error); !ok || !IsNotFound(err) {
+				t.Errorf("SearchUntil(%d, %d) should not find the pattern but got %v", testCase.cursor, testCase.limit, got)
+			}
+		} else if got != testCase.expected {
+			t.Errorf("SearchUntil(%d, %d) should return %d but got %v", testCase.cursor, testCase.limit, testCase.expected, got)
+		}
+	}
+}
+
+func TestSplitOffset(t *testing.T) {
+	for _, testCase := range []struct {
+		str     string
+		sep     rune
+		pattern string
+		offset  Offset
+		err     string
+	}{
+		{"abc", '/', "abc", Offset{}, ""},
+		{"abc/", '/', "abc", Offset{}, ""},
+		{"abc/e", '/', "abc", Offset{End: true}, ""},
+		{"abc/e-1", '/', "abc", Offset{End: true, N: -1}, ""},
+		{"abc/s+4", '/', "abc", Offset{N: 4}, ""},
+		{"abc/b-2", '/', "abc", Offset{N: -2}, ""},
+		{"abc/+4", '/', "abc", Offset{N: 4}, ""},
+		{"abc/+", '/', "abc", Offset{N: 1}, ""},
+		{`a\/b/e`, '/', `a\/b`, Offset{End: true}, ""},
+		{"a/b?e", '?', "a/b", Offset{End: true}, ""},
+		{"abc/x", '/', "abc", Offset{}, "invalid search offset: x"},
+	} {
+		pattern, offset, err := SplitOffset(testCase.str, testCase.sep)
+		if testCase.err != "" {
+			if err == nil || err.Error() != testCase.err {
+				t.Errorf("SplitOffset(%q) should return error %q but got %v", testCase.str, testCase.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("SplitOffset(%q) should not return error but got %v", testCase.str, err)
+		}
+		if pattern != testCase.pattern || offset != testCase.offset {
+			t.Errorf("SplitOffset(%q) should be %q, %+v but got %q, %+v",
+				testCase.str, testCase.pattern, testCase.offset, pattern, offset)
+		}
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	count := loadSize/9 + 10
+	str := strings.Repeat("xyzabcXYZ", count) + "aaa"
+	offsets := func(start, step, count int) []int64 {
+		xs := make([]int64, count)
+		for i := range xs {
+			xs[i] = int64(start + i*step)
+		}
+		return xs
+	}
+	for _, testCase := range []struct {
+		pattern  string
+		expected []int64
+		err      string
+	}{
+		{"abc", offsets(3, 9, count), ""},
+		{"Zxyz", offsets(8, 9, count-1), ""},
+		{"zabcxyz", nil, ""},
+		{"zABCxyz\\c", offsets(2, 9, count), ""},
+		{"aa", offsets(len(str)-3, 1, 2), ""},
+		{"0xzz", nil, "invalid hex pattern: 0xzz"},
+	} {
+		var got []int64
+		err := FindAll(strings.NewReader(str), testCase.pattern, nil, func(xs []int64) bool {
+			got = append(got, xs...)
+			return true
+		})
+		if testCase.err != "" {
+			if err == nil || err.Error() != testCase.err {
+				t.Errorf("FindAll(%q) should return error %q but got %v", testCase.pattern, testCase.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FindAll(%q) should not return error but got %v", testCase.pattern, err)
+		}
+		if !reflect.DeepEqual(got, testCase.expected) {
+			t.Errorf("FindAll(%q) should find %d offsets but got %d offsets", testCase.pattern, len(testCase.expected), len(got))
+		}
+	}
+	quit := make(chan struct{})
+	close(quit)
+	if err := FindAll(strings.NewReader(str), "abc", quit, nil); err == nil || err.Error() != "search is aborted" {
+		t.Errorf("FindAll should be aborted but got %v", err)
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		bs := make([]byte, 1000)
+		for j := range bs {
+			bs[j] = "abc"[r.Intn(3)]
+		}
+		size := horspoolMinSize + r.Intn(10)
+		start := r.Intn(len(bs) - size)
+		target := string(bs[start : start+size])
+		if i%2 == 0 {
+			copy(bs[r.Intn(len(bs)-size):], target)
+		}
+		m, err := newMatcher(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.skip == nil {
+			t.Fatalf("matcher should use horspool for target of size %d", size)
+		}
+		if got, expected := m.index(bs), bytes.Index(bs, m.target); got != expected {
+			t.Errorf("index should be %d but got %d", expected, got)
+		}
+		if got, expected := m.lastIndex(bs), bytes.LastIndex(bs, m.target); got != expected {
+			t.Errorf("lastIndex should be %d but got %d", expected, got)
+		}
+		if got, expected := m.index(bs[:size-1]), -1; got != expected {
+			t.Errorf("index should be %d but got %d", expected, got)
+		}
+	}
+}
+
+func TestSearcherLongPattern(t *testing.T) {
+	target := strings.Repeat("abcdefgh", 16)
+	str := strings.Repeat("abcdefgh", 3*loadSize/8) + "x" + target + "y"
+	for _, testCase := range []struct {
+		cursor   int64
+		forward  bool
+		expected int64
+	}{
+		{0, true, 8},
+		{loadSize, true, loadSize + 8},
+		{int64(3*loadSize - len(target)), true, int64(3*loadSize + 1)},
+		{int64(len(str) - 1), false, int64(3*loadSize + 1)},
+		{int64(3*loadSize + 1), false, int64(3*loadSize - len(target))},
+	} {
+		s := NewSearcher(strings.NewReader(str))
+		var got interface{}
+		for x := range s.Search(testCase.cursor, target, testCase.forward) {
+			if _, ok := x.(Progress); !ok {
+				got = x
+			}
+		}
+		if got != testCase.expected {
+			t.Errorf("Search(%d, %v) should be %d but got %v", testCase.cursor, testCase.forward, testCase.expected, got)
+		}
+	}
+}
+
+// sizedReader reads the repeated bytes of the size,
+// with the bytes of the head and the tail.
+type sizedReader struct {
+	head, bytes, tail []byte
+	size              int64
+}
+
+func (r *sizedReader) ReadAt(p []byte, offset int64) (int, error) {
+	var n int
+	for n < len(p) && offset < r.size {
+		var m int
+		switch tail := r.size - int64(len(r.tail)); {
+		case offset < int64(len(r.head)):
+			m = copy(p[n:], r.head[offset:])
+		case offset >= tail:
+			m = copy(p[n:], r.tail[offset-tail:])
+		default:
+			m = copy(p[n:], r.bytes[offset%int64(len(r.bytes)):])
+			m = int(mathutil.MinInt64(int64(m), tail-offset))
+		}
+		n, offset = n+m, offset+int64(m)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// benchmarkSize is the size of the file to search in the benchmarks.
+const benchmarkSize = 4 * 1024 * 1024 * 1024
+
+func benchmarkSearch(b *testing.B, pattern, target string, forward bool) {
+	r := &sizedReader{
+		bytes: bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog. "), 1024),
+		size:  benchmarkSize,
+	}
+	cursor := int64(0)
+	if forward {
+		r.tail = []byte(target)
+	} else {
+		r.head, cursor = []byte(target), benchmarkSize
+	}
+	b.SetBytes(benchmarkSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := NewSearcher(r)
+		for x := range s.Search(cursor, pattern, forward) {
+			if err, ok := x.(error); ok {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSearchForward(b *testing.B) {
+	benchmarkSearch(b, "0x00010203", "\x00\x01\x02\x03", true)
+}
+
+func BenchmarkSearchBackward(b *testing.B) {
+	benchmarkSearch(b, "0x00010203", "\x00\x01\x02\x03", false)
+}
+
+func BenchmarkSearchForwardLongPattern(b *testing.B) {
+	benchmarkSearch(b, strings.Repeat("lazy cat ", 8), strings.Repeat("lazy cat ", 8), true)
+}
+
+func BenchmarkSearchBackwardLongPattern(b *testing.B) {
+	benchmarkSearch(b, strings.Repeat("lazy cat ", 8), strings.Repeat("lazy cat ", 8), false)
+}
+
+func BenchmarkSearchForwardIgnoreCase(b *testing.B) {
+	benchmarkSearch(b, "\\cLAZY CAT", "lazy cat", true)
+}
+
+func BenchmarkFindAll(b *testing.B) {
+	r := &sizedReader{
+		bytes: bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog. "), 1024),
+		size:  benchmarkSize,
+	}
+	b.SetBytes(benchmarkSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := FindAll(r, "lazy dog", nil, func([]int64) bool { return true }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestFindStrings(t *testing.T) {
+	utf16 := func(s string) string {
+		var b strings.Builder
+		for _, c := range s {
+			b.WriteRune(c)
+			b.WriteByte(0)
+		}
+		return b.String()
+	}
+	for _, testCase := range []struct {
+		name     string
+		str      string
+		minLen   int
+		expected []String
+	}{
+		{
+			name:   "ascii",
+			str:    "\x00abc\x01hello\x02\x03world",
+			minLen: 4,
+			expected: []String{
+				{5, "ascii", "hello"},
+				{12, "ascii", "world"},
+			},
+		},
+		{
+			name:   "minimum length",
+			str:    "\x00abc\x01hello\x02\x03world",
+			minLen: 3,
+			expected: []String{
+				{1, "ascii", "abc"},
+				{5, "ascii", "hello"},
+				{12, "ascii", "world"},
+			},
+		},
+		{
+			name:   "utf-16le",
+			str:    "\x01" + utf16("hello") + "\xff\xff" + utf16("abc") + "\x00" + utf16("world"),
+			minLen: 4,
+			expected: []String{
+				{1, "utf-16le", "hello"},
+				{20, "utf-16le", "world"},
+			},
+		},
+		{
+			name:   "ascii and utf-16le",
+			str:    "test\x01" + utf16("string") + "\x00sample",
+			minLen: 4,
+			expected: []String{
+				{0, "ascii", "test"},
+				{5, "utf-16le", "string"},
+				{18, "ascii", "sample"},
+			},
+		},
+		{
+			name:   "chunk boundary",
+			str:    strings.Repeat("\x00", loadSize-3) + "foobar\x00\x00" + utf16("baz") + strings.Repeat("\x00", loadSize-3) + utf16("qux"),
+			minLen: 3,
+			expected: []String{
+				{loadSize - 3, "ascii", "foobar"},
+				{loadSize + 5, "utf-16le", "baz"},
+				{2*loadSize + 8, "utf-16le", "qux"},
+			},
+		},
+		{
+			name:   "long string",
+			str:    strings.Repeat("x", maxStringLength+10),
+			minLen: 4,
+			expected: []String{
+				{0, "ascii", strings.Repeat("x", maxStringLength)},
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			var got []String
+			err := FindStrings(strings.NewReader(testCase.str), testCase.minLen, nil, func(xs []String) bool {
+				got = append(got, xs...)
+				return true
+			})
+			if err != nil {
+				t.Errorf("FindStrings should not return error but got %v", err)
+			}
+			if !reflect.DeepEqual(got, testCase.expected) {
+				t.Errorf("FindStrings should find %v but got %v", testCase.expected, got)
+			}
+		})
+	}
+	quit := make(chan struct{})
+	close(quit)
+	if err := FindStrings(strings.NewReader("hello"), 4, quit, nil); err == nil || err.Error() != "scan is aborted" {
+		t.Errorf("FindStrings should be aborted but got %v", err)
+	}
+}
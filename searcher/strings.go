@@ -0,0 +1,123 @@
+package searcher
+
+import (
+	"errors"
+	"io"
+	"sort"
+)
+
+// maxStringLength is the maximum length of the text of a string. The longer
+// runs are reported with the truncated text.
+const maxStringLength = 256
+
+// String is a run of the printable characters found by FindStrings.
+type String struct {
+	Offset   int64
+	Encoding string
+	Text     string
+}
+
+// stringRun is a run of the printable characters being scanned.
+type stringRun struct {
+	start int64
+	size  int
+	text  []byte
+}
+
+// stringScanner scans the runs of the printable ASCII characters and the
+// runs of the UTF-16LE characters at the even and odd offsets.
+type stringScanner struct {
+	minLen  int
+	ascii   stringRun
+	utf16   [2]stringRun
+	prev    byte
+	strings []String
+}
+
+func isPrintable(b byte) bool {
+	return 0x20 <= b && b < 0x7f
+}
+
+func (s *stringScanner) scan(base int64, bs []byte) {
+	for i, b := range bs {
+		offset := base + int64(i)
+		if isPrintable(b) {
+			s.ascii.append(offset, 1, b)
+		} else {
+			s.flush(&s.ascii, "ascii")
+		}
+		if offset > 0 {
+			run := &s.utf16[(offset-1)&1]
+			if isPrintable(s.prev) && b == 0 {
+				run.append(offset-1, 2, s.prev)
+			} else {
+				s.flush(run, "utf-16le")
+			}
+		}
+		s.prev = b
+	}
+}
+
+func (run *stringRun) append(offset int64, width int, b byte) {
+	if run.size == 0 || run.start+int64(run.size*width) != offset {
+		run.start, run.size, run.text = offset, 0, run.text[:0]
+	}
+	run.size++
+	if len(run.text) < maxStringLength {
+		run.text = append(run.text, b)
+	}
+}
+
+func (s *stringScanner) flush(run *stringRun, encoding string) {
+	if run.size >= s.minLen {
+		s.strings = append(s.strings, String{run.start, encoding, string(run.text)})
+	}
+	run.size, run.text = 0, run.text[:0]
+}
+
+// FindStrings finds the runs of the printable ASCII characters and the runs
+// of the UTF-16LE characters at least minLen characters long, and calls the
+// function with the strings ending in each chunk in the order of the offsets.
+// It stops when the quit channel is closed or the function returns false.
+func FindStrings(r io.ReaderAt, minLen int, quit <-chan struct{}, f func([]String) bool) error {
+	if minLen <= 0 {
+		return errors.New("invalid minimum length of strings")
+	}
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
+	if cap(*buf) < loadSize {
+		*buf = make([]byte, loadSize)
+	}
+	bs := (*buf)[:loadSize]
+	s := &stringScanner{minLen: minLen}
+	for base := int64(0); ; base += loadSize {
+		select {
+		case <-quit:
+			return errors.New("scan is aborted")
+		default:
+		}
+		n, err := r.ReadAt(bs, base)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		s.scan(base, bs[:n])
+		last := n < loadSize
+		if last {
+			s.flush(&s.ascii, "ascii")
+			s.flush(&s.utf16[0], "utf-16le")
+			s.flush(&s.utf16[1], "utf-16le")
+		}
+		if len(s.strings) > 0 {
+			sort.SliceStable(s.strings, func(i, j int) bool {
+				return s.strings[i].Offset < s.strings[j].Offset
+			})
+			if !f(s.strings) {
+				return nil
+			}
+			s.strings = nil
+		}
+		if last {
+			return nil
+		}
+	}
+}
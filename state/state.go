@@ -1,8 +1,11 @@
 package state
 
 import (
+	"github.com/itchyny/bed/display"
+	"github.com/itchyny/bed/highlight"
 	"github.com/itchyny/bed/layout"
 	"github.com/itchyny/bed/mode"
+	"github.com/itchyny/bed/option"
 )
 
 // State holds the state of the editor to display the user interface.
@@ -11,10 +14,17 @@ type State struct {
 	PrevMode          mode.Mode
 	WindowStates      map[int]*WindowState
 	Layout            layout.Layout
+	Tabs              []TabState
+	TabIndex          int
+	Highlights        highlight.Highlights
+	Options           option.Options
 	Cmdline           []rune
 	CmdlineCursor     int
 	CompletionResults []string
 	CompletionIndex   int
+	HistoryEntries    []string
+	HistoryIndex      int
+	Quickfix          *QuickfixState
 	SearchMode        rune
 	Error             error
 	ErrorType         int
@@ -22,20 +32,52 @@ type State struct {
 
 // WindowState holds the state of one window.
 type WindowState struct {
-	Name          string
-	Modified      bool
-	Width         int
-	Offset        int64
-	Cursor        int64
-	Bytes         []byte
-	Size          int
-	Length        int64
-	Mode          mode.Mode
-	Pending       bool
-	PendingByte   byte
-	VisualStart   int64
-	EditedIndices []int64
-	FocusText     bool
+	Name           string
+	Modified       bool
+	Width          int
+	Offset         int64
+	Cursor         int64
+	Bytes          []byte
+	Size           int
+	Length         int64
+	Mode           mode.Mode
+	Pending        bool
+	PendingByte    byte
+	PendingDigits  int
+	Display        display.Display
+	VisualStart    int64
+	MatchStart     int64
+	MatchEnd       int64
+	Searching      bool
+	SearchProgress int
+	EditedIndices  []int64
+	FocusText      bool
+}
+
+// TabState holds the state of one tab page.
+type TabState struct {
+	Name        string
+	Modified    bool
+	WindowCount int
+}
+
+// QuickfixState holds the state of the quickfix list shown at the bottom
+// of the windows. The entries are the visible part of the list.
+type QuickfixState struct {
+	Title   string
+	Entries []QuickfixEntry
+	Top     int
+	Index   int
+	Total   int
+	Height  int
+	Running bool
+	Focused bool
+}
+
+// QuickfixEntry holds the offset and the context of an entry.
+type QuickfixEntry struct {
+	Offset int64
+	Text   string
 }
 
 // Message types
@@ -4,15 +4,32 @@ import (
 	"github.com/gdamore/tcell"
 
 	"github.com/itchyny/bed/key"
+	"github.com/itchyny/bed/mode"
 )
 
-func eventToKey(event *tcell.EventKey) key.Key {
+// eventToKey converts the key event to the key. The shifted keys are
+// distinguished only in the command line.
+func eventToKey(event *tcell.EventKey, m mode.Mode) key.Key {
+	cmdline := m == mode.Cmdline || m == mode.Search
+	if cmdline && event.Modifiers()&tcell.ModShift != 0 {
+		if key, ok := shiftKeyMap[event.Key()]; ok {
+			return key
+		}
+	}
 	if key, ok := keyMap[event.Key()]; ok {
 		return key
 	}
+	if cmdline && event.Modifiers()&tcell.ModAlt != 0 {
+		return key.Key("m-" + string(event.Rune()))
+	}
 	return key.Key(event.Rune())
 }
 
+var shiftKeyMap = map[tcell.Key]key.Key{
+	tcell.KeyLeft:  key.Key("s-left"),
+	tcell.KeyRight: key.Key("s-right"),
+}
+
 var keyMap = map[tcell.Key]key.Key{
 	tcell.KeyF1:  key.Key("f1"),
 	tcell.KeyF2:  key.Key("f2"),
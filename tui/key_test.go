@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+
+	"github.com/itchyny/bed/key"
+	"github.com/itchyny/bed/mode"
+)
+
+func TestEventToKey(t *testing.T) {
+	for _, testCase := range []struct {
+		key      tcell.Key
+		ch       rune
+		mod      tcell.ModMask
+		mode     mode.Mode
+		expected key.Key
+	}{
+		{tcell.KeyRune, 'b', tcell.ModNone, mode.Cmdline, "b"},
+		{tcell.KeyRune, 'b', tcell.ModAlt, mode.Cmdline, "m-b"},
+		{tcell.KeyRune, 'f', tcell.ModAlt, mode.Search, "m-f"},
+		{tcell.KeyRune, 'b', tcell.ModAlt, mode.Normal, "b"},
+		{tcell.KeyRune, 'x', tcell.ModAlt, mode.Insert, "x"},
+		{tcell.KeyLeft, 0, tcell.ModNone, mode.Cmdline, "left"},
+		{tcell.KeyLeft, 0, tcell.ModShift, mode.Cmdline, "s-left"},
+		{tcell.KeyRight, 0, tcell.ModShift, mode.Search, "s-right"},
+		{tcell.KeyLeft, 0, tcell.ModShift, mode.Normal, "left"},
+		{tcell.KeyRight, 0, tcell.ModShift, mode.Insert, "right"},
+		{tcell.KeyUp, 0, tcell.ModShift, mode.Cmdline, "up"},
+		{tcell.KeyCtrlR, 0, tcell.ModNone, mode.Cmdline, "c-r"},
+	} {
+		if got := eventToKey(tcell.NewEventKey(testCase.key, testCase.ch, testCase.mod), testCase.mode); got != testCase.expected {
+			t.Errorf("eventToKey should return %q but got %q", testCase.expected, got)
+		}
+	}
+}
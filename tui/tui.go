@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -8,19 +10,24 @@ import (
 	"github.com/mattn/go-runewidth"
 
 	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/highlight"
 	"github.com/itchyny/bed/key"
 	"github.com/itchyny/bed/layout"
+	"github.com/itchyny/bed/mathutil"
 	"github.com/itchyny/bed/mode"
+	"github.com/itchyny/bed/option"
 	"github.com/itchyny/bed/state"
 )
 
 // Tui implements UI
 type Tui struct {
-	eventCh chan<- event.Event
-	mode    mode.Mode
-	screen  tcell.Screen
-	waitCh  chan struct{}
-	mu      *sync.Mutex
+	eventCh    chan<- event.Event
+	mode       mode.Mode
+	screen     tcell.Screen
+	highlights highlight.Highlights
+	options    option.Options
+	waitCh     chan struct{}
+	mu         *sync.Mutex
 }
 
 // NewTui creates a new Tui.
@@ -48,7 +55,7 @@ func (ui *Tui) Run(kms map[mode.Mode]*key.Manager) {
 		// Be careful with data races here.
 		switch ev := e.(type) {
 		case *tcell.EventKey:
-			if e := kms[ui.getMode()].Press(eventToKey(ev)); e.Type != event.Nop {
+			if e := kms[ui.getMode()].Press(eventToKey(ev, ui.getMode())); e.Type != event.Nop {
 				ui.eventCh <- e
 			} else {
 				ui.eventCh <- event.Event{Type: event.Rune, Rune: ev.Rune()}
@@ -80,8 +87,14 @@ func (ui *Tui) Redraw(s state.State) error {
 	ui.mu.Lock()
 	defer ui.mu.Unlock()
 	ui.mode = s.Mode
+	if ui.highlights = s.Highlights; ui.highlights == nil {
+		ui.highlights = highlight.Default()
+	}
+	ui.options = s.Options
 	ui.screen.Clear()
-	ui.drawWindows(s.WindowStates, s.Layout)
+	ui.drawTabLine(s)
+	ui.drawWindows(s.WindowStates, s.Layout, s.Quickfix == nil || !s.Quickfix.Focused)
+	ui.drawQuickfix(s)
 	ui.drawCmdline(s)
 	ui.screen.Show()
 	return nil
@@ -94,53 +107,128 @@ func (ui *Tui) setLine(line int, offset int, str string, style tcell.Style) {
 	}
 }
 
-func (ui *Tui) drawWindows(windowStates map[int]*state.WindowState, l layout.Layout) {
+func (ui *Tui) drawTabLine(s state.State) {
+	if len(s.Tabs) <= 1 {
+		return
+	}
+	width, _ := ui.Size()
+	ui.setLine(0, 0, strings.Repeat(" ", width), ui.highlights[highlight.TabLine].Style())
+	var offset int
+	for i, tab := range s.Tabs {
+		label := " "
+		if tab.WindowCount > 1 {
+			label += strconv.Itoa(tab.WindowCount)
+		}
+		if tab.Modified {
+			label += "+"
+		}
+		if label != " " {
+			label += " "
+		}
+		if tab.Name == "" {
+			label += "[No name] "
+		} else {
+			label += tab.Name + " "
+		}
+		style := ui.highlights[highlight.TabLine].Style()
+		if i == s.TabIndex {
+			style = ui.highlights[highlight.TabLineSel].Style()
+		}
+		ui.setLine(0, offset, label, style)
+		offset += runewidth.StringWidth(label)
+	}
+}
+
+func (ui *Tui) drawWindows(windowStates map[int]*state.WindowState, l layout.Layout, active bool) {
 	switch l := l.(type) {
 	case layout.Window:
 		r := fromLayout(l)
 		if r.valid() {
 			ui.newTuiWindow(r).drawWindow(
 				windowStates[l.Index],
-				l.Active && ui.mode != mode.Cmdline && ui.mode != mode.Search,
+				active && l.Active && ui.mode != mode.Cmdline && ui.mode != mode.Search,
 			)
 		}
 	case layout.Horizontal:
-		ui.drawWindows(windowStates, l.Top)
-		ui.drawWindows(windowStates, l.Bottom)
+		ui.drawWindows(windowStates, l.Top, active)
+		ui.drawWindows(windowStates, l.Bottom, active)
 	case layout.Vertical:
-		ui.drawWindows(windowStates, l.Left)
-		ui.drawWindows(windowStates, l.Right)
+		ui.drawWindows(windowStates, l.Left, active)
+		ui.drawWindows(windowStates, l.Right, active)
 		ui.drawVerticalSplit(fromLayout(l.Left))
 	}
 }
 
 func (ui *Tui) newTuiWindow(region region) *tuiWindow {
-	return &tuiWindow{region: region, screen: ui.screen}
+	return &tuiWindow{region: region, screen: ui.screen, highlights: ui.highlights, options: ui.options}
 }
 
 func (ui *Tui) drawVerticalSplit(region region) {
 	for i := 0; i < region.height; i++ {
-		ui.setLine(region.top+i, region.left+region.width, "|", tcell.StyleDefault.Reverse(true))
+		ui.setLine(region.top+i, region.left+region.width, "|", ui.highlights[highlight.VertSplit].Style())
+	}
+}
+
+func (ui *Tui) drawQuickfix(s state.State) {
+	qf := s.Quickfix
+	if qf == nil {
+		return
+	}
+	width, height := ui.Size()
+	top := height - 1 - qf.Height
+	w := ui.newTuiWindow(region{left: 0, top: top, height: qf.Height, width: width})
+	for i := 0; i < qf.Height-1; i++ {
+		var line string
+		style := tcell.StyleDefault
+		if i < len(qf.Entries) {
+			entry := qf.Entries[i]
+			line = fmt.Sprintf(" %s: %s", w.formatOffset(entry.Offset, 8, false), entry.Text)
+			if qf.Top+i == qf.Index {
+				style = ui.highlights[highlight.Search].Style()
+			}
+		}
+		line += strings.Repeat(" ", mathutil.MaxInt(width-runewidth.StringWidth(line), 0))
+		ui.setLine(top+i, 0, line, style)
+	}
+	left := " [Quickfix List] " + qf.Title
+	right := fmt.Sprintf("%d/%d ", qf.Index+1, qf.Total)
+	if qf.Running {
+		right = fmt.Sprintf("%d matches, searching... ", qf.Total)
 	}
+	line := left + strings.Repeat(" ", mathutil.MaxInt(2, width-runewidth.StringWidth(left)-len(right))) + right
+	style := ui.highlights[highlight.StatusLine].Style()
+	if !qf.Focused {
+		style = ui.highlights[highlight.StatusLineNC].Style()
+	}
+	ui.setLine(height-2, 0, line, style)
 }
 
 func (ui *Tui) drawCmdline(s state.State) {
 	width, height := ui.Size()
 	if s.Error != nil {
-		style := tcell.StyleDefault.Foreground(tcell.ColorRed)
+		style := ui.highlights[highlight.ErrorMsg].Style()
 		if s.ErrorType == state.MessageInfo {
-			style = style.Foreground(tcell.ColorYellow)
+			style = ui.highlights[highlight.InfoMsg].Style()
+		}
+		// multiple lines of the message are drawn over the windows
+		lines := strings.Split(s.Error.Error(), "\n")
+		for i, line := range lines {
+			if i < len(lines)-1 {
+				line += strings.Repeat(" ", mathutil.MaxInt(width-runewidth.StringWidth(line), 0))
+			}
+			ui.setLine(height-len(lines)+i, 0, line, style)
 		}
-		ui.setLine(height-1, 0, s.Error.Error(), style)
 	} else if s.Mode == mode.Cmdline || s.PrevMode == mode.Cmdline && len(s.Cmdline) > 0 {
 		ui.setLine(height-1, 0, ":"+string(s.Cmdline), tcell.StyleDefault)
 		if s.Mode == mode.Cmdline {
 			ui.drawCompletionResults(s, width, height)
+			ui.drawHistoryEntries(s, width, height)
 			ui.screen.ShowCursor(1+runewidth.StringWidth(string(s.Cmdline[:s.CmdlineCursor])), height-1)
 		}
 	} else if s.SearchMode != '\x00' {
 		ui.setLine(height-1, 0, string(s.SearchMode)+string(s.Cmdline), tcell.StyleDefault)
 		if s.Mode == mode.Search {
+			ui.drawHistoryEntries(s, width, height)
 			ui.screen.ShowCursor(1+runewidth.StringWidth(string(s.Cmdline[:s.CmdlineCursor])), height-1)
 		}
 	}
@@ -161,11 +249,27 @@ func (ui *Tui) drawCompletionResults(s state.State, width int, height int) {
 			line += " " + result + " "
 			lineWidth += w + 2
 		}
-		ui.setLine(height-2, 0, line+strings.Repeat(" ", width), tcell.StyleDefault.Reverse(true))
+		ui.setLine(height-2, 0, line+strings.Repeat(" ", width), ui.highlights[highlight.Pmenu].Style())
 		if s.CompletionIndex >= 0 {
 			ui.setLine(height-2, pos, " "+s.CompletionResults[s.CompletionIndex]+" ",
-				tcell.StyleDefault.Foreground(tcell.ColorGrey).Reverse(true))
+				ui.highlights[highlight.PmenuSel].Style())
+		}
+	}
+}
+
+// drawHistoryEntries draws the history window over the bottom half of the
+// windows, scrolled to keep the selected entry visible.
+func (ui *Tui) drawHistoryEntries(s state.State, width int, height int) {
+	rows := mathutil.MinInt(len(s.HistoryEntries), mathutil.MaxInt((height-1)/2, 1))
+	start := mathutil.MaxInt(0, mathutil.MinInt(len(s.HistoryEntries)-rows, s.HistoryIndex))
+	for i := 0; i < rows; i++ {
+		style := ui.highlights[highlight.Pmenu].Style()
+		if start+i == s.HistoryIndex {
+			style = ui.highlights[highlight.PmenuSel].Style()
 		}
+		line := " " + s.HistoryEntries[start+i]
+		line += strings.Repeat(" ", mathutil.MaxInt(width-runewidth.StringWidth(line), 0))
+		ui.setLine(height-1-rows+i, 0, line, style)
 	}
 }
 
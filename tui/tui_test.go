@@ -7,10 +7,13 @@ import (
 
 	"github.com/gdamore/tcell"
 
+	"github.com/itchyny/bed/display"
 	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/highlight"
 	"github.com/itchyny/bed/key"
 	"github.com/itchyny/bed/layout"
 	"github.com/itchyny/bed/mode"
+	"github.com/itchyny/bed/option"
 	"github.com/itchyny/bed/state"
 )
 
@@ -132,6 +135,14 @@ func TestTuiEmpty(t *testing.T) {
 		" [No name] : 0x00 : '\\x00'                                0/0 : 0x000000/0x000000 : 0.00%",
 	})
 
+	s.WindowStates[0].Searching, s.WindowStates[0].SearchProgress = true, 42
+	if err := ui.Redraw(s); err != nil {
+		t.Errorf("ui.Redraw should return nil but got: %v", err)
+	}
+	shouldContain(t, screen, []string{
+		" [No name] : 0x00 : '\\x00' : searching 42%                0/0 : 0x000000/0x000000 : 0.00%",
+	})
+
 	x, y, visible := screen.GetCursor()
 	if x != 10 || y != 1 {
 		t.Errorf("cursor position should be (%d, %d) but got (%d, %d)", 10, 1, x, y)
@@ -144,6 +155,160 @@ func TestTuiEmpty(t *testing.T) {
 	}
 }
 
+func TestTuiDisplay(t *testing.T) {
+	ui := NewTui()
+	eventCh := make(chan event.Event)
+	screen := tcell.NewSimulationScreen("")
+	if err := ui.initForTest(eventCh, screen); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(90, 20)
+	width, height := screen.Size()
+	go ui.Run(mockKeyManager())
+
+	for _, testCase := range []struct {
+		display  string
+		width    int
+		pending  int
+		expected []string
+		x        int
+	}{
+		{
+			"binary", 4, 3,
+			[]string{
+				"        |        0        1        2        3 |",
+				" 000000 | 01000001 11000000 00000000 00000000 | A... #",
+			},
+			22,
+		},
+		{
+			"decimal", 8, 0,
+			[]string{
+				"        |   0   1   2   3   4   5   6   7 |",
+				" 000000 |  65 192   0   0   0   0   0   0 | A....... #",
+			},
+			14,
+		},
+		{
+			"word32le", 8, 1,
+			[]string{
+				"        |  3 2 1 0  7 6 5 4 |",
+				" 000000 | 0000c041 00000000 | A....... #",
+			},
+			15,
+		},
+	} {
+		d, _ := display.Lookup(testCase.display)
+		s := state.State{
+			WindowStates: map[int]*state.WindowState{
+				0: &state.WindowState{
+					Width:         testCase.width,
+					Cursor:        1,
+					Bytes:         []byte("A\xc0" + strings.Repeat("\x00", 14)),
+					Size:          16,
+					Length:        16,
+					Mode:          mode.Insert,
+					PendingDigits: testCase.pending,
+					Display:       d,
+					VisualStart:   -1,
+				},
+			},
+			Layout: layout.NewLayout(0).Resize(0, 0, width, height-1),
+		}
+		if err := ui.Redraw(s); err != nil {
+			t.Errorf("ui.Redraw should return nil but got: %v", err)
+		}
+		shouldContain(t, screen, testCase.expected)
+		if x, y, _ := screen.GetCursor(); x != testCase.x || y != 1 {
+			t.Errorf("cursor position should be (%d, %d) but got (%d, %d)", testCase.x, 1, x, y)
+		}
+	}
+	if err := ui.Close(); err != nil {
+		t.Errorf("ui.Close should return nil but got %v", err)
+	}
+}
+
+func TestTuiOffset(t *testing.T) {
+	ui := NewTui()
+	eventCh := make(chan event.Event)
+	screen := tcell.NewSimulationScreen("")
+	if err := ui.initForTest(eventCh, screen); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(90, 20)
+	width, height := screen.Size()
+	go ui.Run(mockKeyManager())
+
+	for _, testCase := range []struct {
+		options  option.Options
+		expected []string
+		x        int
+	}{
+		{
+			option.Options{OffsetBase: "hex", OffsetOrigin: 0x8000000},
+			[]string{
+				"          |  0  1  2  3  4  5  6  7 |",
+				" 08000000 | 00 00 00 00 00 00 00 00 | ........ #",
+				" 08000008 | 00 00 00 00 00 00 00 00 | ........ #",
+				"9/16 : 0x08000009/0x08000010 : 56.25%",
+			},
+			15,
+		},
+		{
+			option.Options{OffsetBase: "dec", OffsetOrigin: 100},
+			[]string{
+				" 000100 | 00 00 00 00 00 00 00 00 | ........ #",
+				" 000108 | 00 00 00 00 00 00 00 00 | ........ #",
+				"9/16 : 000109/000116 : 56.25%",
+			},
+			13,
+		},
+		{
+			option.Options{OffsetBase: "oct"},
+			[]string{
+				" 000010 | 00 00 00 00 00 00 00 00 | ........ #",
+				"9/16 : 0o000011/0o000020 : 56.25%",
+			},
+			13,
+		},
+		{
+			option.Options{OffsetBase: "none"},
+			[]string{
+				"  0  1  2  3  4  5  6  7 |",
+				"\n 00 00 00 00 00 00 00 00 | ........ #",
+				"9/16 : 0x000009/0x000010 : 56.25%",
+			},
+			4,
+		},
+	} {
+		s := state.State{
+			WindowStates: map[int]*state.WindowState{
+				0: &state.WindowState{
+					Width:       8,
+					Cursor:      9,
+					Bytes:       []byte(strings.Repeat("\x00", 16)),
+					Size:        16,
+					Length:      16,
+					Mode:        mode.Normal,
+					VisualStart: -1,
+				},
+			},
+			Layout:  layout.NewLayout(0).Resize(0, 0, width, height-1),
+			Options: testCase.options,
+		}
+		if err := ui.Redraw(s); err != nil {
+			t.Errorf("ui.Redraw should return nil but got: %v", err)
+		}
+		shouldContain(t, screen, testCase.expected)
+		if x, y, _ := screen.GetCursor(); x != testCase.x || y != 2 {
+			t.Errorf("cursor position should be (%d, %d) but got (%d, %d)", testCase.x, 2, x, y)
+		}
+	}
+	if err := ui.Close(); err != nil {
+		t.Errorf("ui.Close should return nil but got %v", err)
+	}
+}
+
 func TestTuiScrollBar(t *testing.T) {
 	ui := NewTui()
 	eventCh := make(chan event.Event)
@@ -368,6 +533,25 @@ func TestTuiCmdline(t *testing.T) {
 	if !strings.HasPrefix(got, expected) {
 		t.Errorf("cmdline should start with %q but got %q", expected, got)
 	}
+
+	s.Error = errors.New("first line\nsecond line")
+	if err := ui.Redraw(s); err != nil {
+		t.Errorf("ui.Redraw should return nil but got: %v", err)
+	}
+
+	got, expected = getCmdline(), "second line "
+	if !strings.HasPrefix(got, expected) {
+		t.Errorf("cmdline should start with %q but got %q", expected, got)
+	}
+	cells, _, _ := screen.GetContents()
+	var runes []rune
+	for _, cell := range cells[20*13 : 20*14] {
+		runes = append(runes, cell.Runes...)
+	}
+	got, expected = string(runes), "first line          "
+	if got != expected {
+		t.Errorf("message line should be %q but got %q", expected, got)
+	}
 	if err := ui.Close(); err != nil {
 		t.Errorf("ui.Close should return nil but got %v", err)
 	}
@@ -413,3 +597,264 @@ func TestTuiCmdlineCompletionCandidates(t *testing.T) {
 		t.Errorf("ui.Close should return nil but got %v", err)
 	}
 }
+
+func TestTuiCmdlineHistory(t *testing.T) {
+	ui := NewTui()
+	eventCh := make(chan event.Event)
+	screen := tcell.NewSimulationScreen("")
+	if err := ui.initForTest(eventCh, screen); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(20, 9)
+	go ui.Run(mockKeyManager())
+
+	s := state.State{
+		Mode:           mode.Search,
+		SearchMode:     '/',
+		Cmdline:        []rune("ghi"),
+		CmdlineCursor:  3,
+		HistoryEntries: []string{"abc", "def", "ghi", "jkl", "mno", "pqr"},
+		HistoryIndex:   2,
+	}
+	if err := ui.Redraw(s); err != nil {
+		t.Errorf("ui.Redraw should return nil but got: %v", err)
+	}
+
+	got, expected := getContents(screen)[21*4:], " ghi                \n"+
+		" jkl                \n"+
+		" mno                \n"+
+		" pqr                \n"+
+		"/ghi                \n"
+	if got != expected {
+		t.Errorf("history window should be %q but got %q", expected, got)
+	}
+
+	s.HistoryIndex, s.Cmdline, s.CmdlineCursor = 5, []rune("pqr"), 3
+	if err := ui.Redraw(s); err != nil {
+		t.Errorf("ui.Redraw should return nil but got: %v", err)
+	}
+	shouldContain(t, screen, []string{" pqr", "/pqr"})
+	if err := ui.Close(); err != nil {
+		t.Errorf("ui.Close should return nil but got %v", err)
+	}
+}
+
+func TestTuiQuickfix(t *testing.T) {
+	ui := NewTui()
+	eventCh := make(chan event.Event)
+	screen := tcell.NewSimulationScreen("")
+	if err := ui.initForTest(eventCh, screen); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(60, 9)
+	go ui.Run(mockKeyManager())
+
+	s := state.State{
+		Quickfix: &state.QuickfixState{
+			Title: ":findall ab",
+			Entries: []state.QuickfixEntry{
+				{Offset: 0x10, Text: "61 62  ab"},
+				{Offset: 0x20, Text: "61 62 63  abc"},
+			},
+			Top:    1,
+			Index:  2,
+			Total:  3,
+			Height: 4,
+		},
+	}
+	if err := ui.Redraw(s); err != nil {
+		t.Errorf("ui.Redraw should return nil but got: %v", err)
+	}
+
+	got, expected := getContents(screen)[61*4:], " 00000010: 61 62  ab                                        \n"+
+		" 00000020: 61 62 63  abc                                    \n"+
+		"                                                            \n"+
+		" [Quickfix List] :findall ab                            3/3 \n"+
+		"                                                            \n"
+	if got != expected {
+		t.Errorf("quickfix list should be %q but got %q", expected, got)
+	}
+
+	s.Options.OffsetBase, s.Quickfix.Running = "dec", true
+	if err := ui.Redraw(s); err != nil {
+		t.Errorf("ui.Redraw should return nil but got: %v", err)
+	}
+	shouldContain(t, screen, []string{" 00000016: 61 62  ab", "3 matches, searching... "})
+	if err := ui.Close(); err != nil {
+		t.Errorf("ui.Close should return nil but got %v", err)
+	}
+}
+
+func TestTuiTabLine(t *testing.T) {
+	ui := NewTui()
+	eventCh := make(chan event.Event)
+	screen := tcell.NewSimulationScreen("")
+	if err := ui.initForTest(eventCh, screen); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(90, 20)
+	width, height := screen.Size()
+	go ui.Run(mockKeyManager())
+
+	s := state.State{
+		WindowStates: map[int]*state.WindowState{
+			0: &state.WindowState{
+				Name:   "test",
+				Width:  16,
+				Bytes:  []byte(strings.Repeat("\x00", 16*(height-2))),
+				Size:   16 * (height - 2),
+				Length: 0,
+				Mode:   mode.Normal,
+			},
+		},
+		Layout: layout.NewLayout(0).Resize(0, 1, width, height-2),
+		Tabs: []state.TabState{
+			{Name: "test", WindowCount: 1},
+			{Name: "", Modified: true, WindowCount: 1},
+			{Name: "sample", Modified: true, WindowCount: 3},
+		},
+		TabIndex: 0,
+	}
+	if err := ui.Redraw(s); err != nil {
+		t.Errorf("ui.Redraw should return nil but got: %v", err)
+	}
+
+	got := strings.Split(getContents(screen), "\n")
+	if expected := " test  + [No name]  3+ sample " + strings.Repeat(" ", 60); got[0] != expected {
+		t.Errorf("tab line should be %q but got %q", expected, got[0])
+	}
+	if expected := "        |  0  1  2  3"; !strings.HasPrefix(got[1], expected) {
+		t.Errorf("window should start with %q but got %q", expected, got[1])
+	}
+	if err := ui.Close(); err != nil {
+		t.Errorf("ui.Close should return nil but got %v", err)
+	}
+}
+
+func TestTuiColorBytes(t *testing.T) {
+	ui := NewTui()
+	eventCh := make(chan event.Event)
+	screen := tcell.NewSimulationScreen("")
+	if err := ui.initForTest(eventCh, screen); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(90, 20)
+	width, height := screen.Size()
+	go ui.Run(mockKeyManager())
+
+	s := state.State{
+		WindowStates: map[int]*state.WindowState{
+			0: &state.WindowState{
+				Width:       16,
+				Cursor:      17,
+				Bytes:       []byte("\x00A \x01\x80\xff" + strings.Repeat("\x00", 26)),
+				Size:        32,
+				Length:      32,
+				Mode:        mode.Normal,
+				VisualStart: -1,
+			},
+		},
+		Layout:  layout.NewLayout(0).Resize(0, 0, width, height-1),
+		Options: option.Options{ColorBytes: true, Crosshair: true},
+	}
+	hs := highlight.Default()
+	for _, testCase := range []struct {
+		options option.Options
+		x, y    int
+		group   []string
+	}{
+		{option.Options{ColorBytes: true}, 10, 1, []string{highlight.NullByte}},
+		{option.Options{ColorBytes: true}, 13, 1, []string{highlight.AsciiPrintable}},
+		{option.Options{ColorBytes: true}, 61, 1, []string{highlight.AsciiPrintable}},
+		{option.Options{ColorBytes: true}, 16, 1, []string{highlight.AsciiWhitespace}},
+		{option.Options{ColorBytes: true}, 19, 1, []string{highlight.AsciiOther}},
+		{option.Options{ColorBytes: true}, 22, 1, []string{highlight.NonAscii}},
+		{option.Options{ColorBytes: true}, 25, 1, []string{highlight.FFByte}},
+		{option.Options{}, 25, 1, []string{}},
+		{option.Options{Crosshair: true}, 10, 1, []string{}},
+		{option.Options{Crosshair: true}, 13, 1, []string{highlight.CursorColumn}},
+		{option.Options{Crosshair: true}, 10, 2, []string{highlight.CursorLine}},
+		{option.Options{Crosshair: true}, 75, 2, []string{highlight.CursorLine}},
+		{option.Options{Crosshair: true}, 13, 2, []string{highlight.Cursor}},
+		{option.Options{Crosshair: true}, 61, 2, []string{highlight.CursorNC}},
+	} {
+		s.Options = testCase.options
+		if err := ui.Redraw(s); err != nil {
+			t.Errorf("ui.Redraw should return nil but got: %v", err)
+		}
+		var h highlight.Highlight
+		for _, group := range testCase.group {
+			h = h.Merge(hs[group])
+		}
+		if _, _, style, _ := screen.GetContent(testCase.x, testCase.y); style != h.Style() {
+			t.Errorf("style at (%d, %d) should be %v but got %v", testCase.x, testCase.y, h.Style(), style)
+		}
+	}
+	s.Options = option.Options{}
+	s.WindowStates[0].MatchStart, s.WindowStates[0].MatchEnd = 1, 3
+	if err := ui.Redraw(s); err != nil {
+		t.Errorf("ui.Redraw should return nil but got: %v", err)
+	}
+	for _, testCase := range []struct {
+		x, y  int
+		style tcell.Style
+	}{
+		{10, 1, tcell.StyleDefault},
+		{13, 1, hs[highlight.Search].Style()},
+		{16, 1, hs[highlight.Search].Style()},
+		{62, 1, hs[highlight.Search].Style()},
+		{19, 1, tcell.StyleDefault},
+	} {
+		if _, _, style, _ := screen.GetContent(testCase.x, testCase.y); style != testCase.style {
+			t.Errorf("style at (%d, %d) should be %v but got %v", testCase.x, testCase.y, testCase.style, style)
+		}
+	}
+	if err := ui.Close(); err != nil {
+		t.Errorf("ui.Close should return nil but got %v", err)
+	}
+}
+
+func TestTuiWindowEncoding(t *testing.T) {
+	for _, testCase := range []struct {
+		encoding string
+		offset   int64
+		bytes    string
+		expected string
+	}{
+		{"utf-8", 0, "A\x00\xe3\x81\x82\xf0\x9f\x98\x80\xe3\x81", "A.あ_ 😀_  .."},
+		{"utf-8", 0, "\xe3\x81\x82\xe3\x81\x82\xe3\x81\x82\xe3\x81\x82", "あ_ .  あ_ あ_ "},
+		{"utf-16le", 0, "A\x00\x42\x30\x3d\xd8\x00\xde\x3d\xd8", "A あ_😀_  . "},
+		{"utf-16be", 1, "\x00\x00A\x30\x42\xd8\x3d", ".A . . "},
+		{"latin1", 0, "A\xe9\xa0", "Aé."},
+		{"cp437", 0, "\xdb\xb0\x01", "█░."},
+		{"ebcdic", 0, "\xc1\x81\x40\x25", "Aa ."},
+		{"shift-jis", 0, "a\x82\xa0\xb1\x82", "aあ_ｱ."},
+	} {
+		width := 4
+		ui := &tuiWindow{options: option.Options{Encoding: testCase.encoding}}
+		s := &state.WindowState{
+			Width:       width,
+			Offset:      testCase.offset,
+			Cursor:      testCase.offset,
+			Bytes:       []byte(testCase.bytes),
+			Size:        len(testCase.bytes),
+			Length:      testCase.offset + int64(len(testCase.bytes)),
+			VisualStart: -1,
+		}
+		var got string
+		for _, row := range ui.cellsArray((len(testCase.bytes)+width-1)/width, width, s) {
+			for _, c := range row {
+				if c.eof {
+					break
+				} else if c.text == "" {
+					got += "_"
+				} else {
+					got += c.text
+				}
+			}
+		}
+		if got != testCase.expected {
+			t.Errorf("text column in %s should be %q but got %q", testCase.encoding, testCase.expected, got)
+		}
+	}
+}
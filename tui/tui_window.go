@@ -2,20 +2,28 @@ package tui
 
 import (
 	"fmt"
-	"math"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
 
+	"github.com/itchyny/bed/charset"
+	"github.com/itchyny/bed/display"
+	"github.com/itchyny/bed/highlight"
 	"github.com/itchyny/bed/mathutil"
 	"github.com/itchyny/bed/mode"
+	"github.com/itchyny/bed/option"
 	"github.com/itchyny/bed/state"
 )
 
 type tuiWindow struct {
-	region region
-	screen tcell.Screen
+	region     region
+	screen     tcell.Screen
+	highlights highlight.Highlights
+	options    option.Options
 }
 
 func (ui *tuiWindow) getTextDrawer() *textDrawer {
@@ -26,130 +34,262 @@ func (ui *tuiWindow) setCursor(line int, offset int) {
 	ui.screen.ShowCursor(ui.region.left+offset, ui.region.top+line)
 }
 
+func (ui *tuiWindow) style(groups ...string) tcell.Style {
+	var h highlight.Highlight
+	for _, group := range groups {
+		h = h.Merge(ui.highlights[group])
+	}
+	return h.Style()
+}
+
+// offsetStyleWidth returns the number of the digits of the offsets,
+// which is enough for the offset of the end with the origin.
 func (ui *tuiWindow) offsetStyleWidth(s *state.WindowState) int {
-	threshold := int64(0xfffff)
-	for i := 0; i < 10; i++ {
-		if s.Length <= threshold {
-			return 6 + i
+	base := 16
+	switch ui.options.OffsetBase {
+	case "dec":
+		base = 10
+	case "oct":
+		base = 8
+	}
+	return mathutil.MaxInt(len(strconv.FormatInt(ui.options.OffsetOrigin+s.Length, base))+1, 6)
+}
+
+// formatOffset formats the offset with the origin in the base of the offset.
+func (ui *tuiWindow) formatOffset(offset int64, width int, prefix bool) string {
+	offset += ui.options.OffsetOrigin
+	switch ui.options.OffsetBase {
+	case "dec":
+		return fmt.Sprintf("%0*d", width, offset)
+	case "oct":
+		if prefix {
+			return fmt.Sprintf("0o%0*o", width, offset)
 		}
-		threshold = (threshold << 4) | 0x0f
+		return fmt.Sprintf("%0*o", width, offset)
+	default:
+		if prefix {
+			return fmt.Sprintf("0x%0*x", width, offset)
+		}
+		return fmt.Sprintf("%0*x", width, offset)
+	}
+}
+
+// hexLeft returns the left position of the hex column,
+// which is next to the offset column unless it is hidden.
+func (ui *tuiWindow) hexLeft(offsetStyleWidth int) int {
+	if ui.options.OffsetBase == "none" {
+		return 0
 	}
-	return 16
+	return offsetStyleWidth + 3
 }
 
 func (ui *tuiWindow) drawWindow(s *state.WindowState, active bool) {
 	height, width := ui.region.height-2, s.Width
-	bytes, styles := ui.bytesArray(height, width, s)
+	cells := ui.cellsArray(height, width, s)
 	cursorPos := int(s.Cursor - s.Offset)
 	cursorLine := cursorPos / width
 	offsetStyleWidth := ui.offsetStyleWidth(s)
-	offsetStyle := " %0" + strconv.Itoa(offsetStyleWidth) + "x"
+	left := ui.hexLeft(offsetStyleWidth)
+	disp := getDisplay(s)
+	hexWidth := disp.Width(width)
+	hexCursor, textCursor := highlight.CursorNC, highlight.CursorNC
+	if active && s.FocusText {
+		textCursor = highlight.Cursor
+	} else if active {
+		hexCursor = highlight.Cursor
+	}
 	d := ui.getTextDrawer()
 	for i := 0; i < height; i++ {
 		d.setTop(i + 1).setLeft(0).setOffset(0)
-		d.setString(fmt.Sprintf(offsetStyle, s.Offset+int64(i*width)), tcell.StyleDefault.Bold(i == cursorLine))
-		d.setLeft(offsetStyleWidth + 3)
+		if left > 0 {
+			offset := " " + ui.formatOffset(s.Offset+int64(i*width), offsetStyleWidth, false)
+			if i == cursorLine {
+				d.setString(offset, ui.style(highlight.Offset, highlight.CursorOffset))
+			} else {
+				d.setString(offset, ui.style(highlight.Offset))
+			}
+			d.setLeft(left).setOffset(-2).setString(" | ", ui.style(highlight.Separator))
+		}
+		d.setLeft(left)
 		for j := 0; j < width; j++ {
-			style := styles[i][j]
-			if style == math.MaxInt16 {
+			c := cells[i][j]
+			if c.eof {
+				if i*width+j == cursorPos {
+					if hexCursor == highlight.CursorNC {
+						d.setOffset(disp.Position(j)).setString(" ", ui.style(hexCursor))
+					}
+					if textCursor == highlight.CursorNC {
+						d.setOffset(hexWidth+j+3).setString(" ", ui.style(textCursor))
+					}
+				}
 				continue
 			}
-			if style == math.MaxInt16-1 {
-				d.setOffset(3*j+1).setString(" ", style.Underline(!active || s.FocusText))
-				d.setOffset(3*width+j+3).setString(" ", style.Underline(!active || !s.FocusText))
-				continue
+			h1, h2 := ui.highlights[highlight.HexByte], ui.highlights[highlight.TextByte]
+			if ui.options.ColorBytes {
+				group := byteHighlight(c.b)
+				h1, h2 = h1.Merge(ui.highlights[group]), h2.Merge(ui.highlights[group])
 			}
-			style1, style2 := style, style
+			if active && ui.options.Crosshair && i*width+j != cursorPos {
+				if i == cursorLine {
+					h1 = h1.Merge(ui.highlights[highlight.CursorLine])
+					h2 = h2.Merge(ui.highlights[highlight.CursorLine])
+				} else if j == cursorPos%width {
+					h1 = h1.Merge(ui.highlights[highlight.CursorColumn])
+					h2 = h2.Merge(ui.highlights[highlight.CursorColumn])
+				}
+			}
+			h1, h2 = h1.Merge(c.highlight), h2.Merge(c.highlight)
 			if i*width+j == cursorPos {
-				style1 = style1.Reverse(active && !s.FocusText).Bold(
-					!active || s.FocusText).Underline(!active || s.FocusText)
-				style2 = style2.Reverse(active && s.FocusText).Bold(
-					!active || !s.FocusText).Underline(!active || !s.FocusText)
+				h1, h2 = h1.Merge(ui.highlights[hexCursor]), h2.Merge(ui.highlights[textCursor])
+			}
+			d.setOffset(disp.Position(j)).setString(disp.Format(c.b), h1.Style())
+			if c.text != "" {
+				d.setOffset(hexWidth+j+3).setString(c.text, h2.Style())
 			}
-			d.setOffset(3*j+1).setString(fmt.Sprintf("%02x", bytes[i][j]), style1)
-			d.setOffset(3*width+j+3).setString(string(prettyByte(bytes[i][j])), style2)
 		}
-		d.setOffset(-2).setString(" | ", tcell.StyleDefault)
-		d.setOffset(3*width).setString(" | ", tcell.StyleDefault)
+		d.setOffset(hexWidth).setString(" | ", ui.style(highlight.Separator))
 	}
 	i := int(s.Cursor % int64(width))
 	if active {
 		if s.FocusText {
-			ui.setCursor(cursorLine+1, 3*width+i+6+offsetStyleWidth)
-		} else if s.Pending {
-			ui.setCursor(cursorLine+1, 3*i+5+offsetStyleWidth)
+			ui.setCursor(cursorLine+1, left+hexWidth+i+3)
 		} else {
-			ui.setCursor(cursorLine+1, 3*i+4+offsetStyleWidth)
+			ui.setCursor(cursorLine+1, left+disp.Position(i)+s.PendingDigits)
 		}
 	}
-	ui.drawHeader(s, offsetStyleWidth)
-	ui.drawScrollBar(s, height, 4*width+7+offsetStyleWidth)
-	ui.drawFooter(s, offsetStyleWidth)
+	ui.drawHeader(s, left)
+	ui.drawScrollBar(s, height, left+hexWidth+width+4)
+	ui.drawFooter(s, offsetStyleWidth, active)
+}
+
+// getDisplay returns the display of the window state, or the default
+// display for the state without the display.
+func getDisplay(s *state.WindowState) display.Display {
+	if s.Display.Size == 0 {
+		return display.Default()
+	}
+	return s.Display
+}
+
+// cell holds the byte and the highlight of the edited or selected byte,
+// which is merged to the highlight of the byte category on drawing.
+// The text is the character in the text column, which is empty for
+// the cell covered by the preceding wide character.
+type cell struct {
+	b         byte
+	text      string
+	highlight highlight.Highlight
+	eof       bool
 }
 
-func (ui *tuiWindow) bytesArray(height, width int, s *state.WindowState) ([][]byte, [][]tcell.Style) {
+func (ui *tuiWindow) cellsArray(height, width int, s *state.WindowState) [][]cell {
 	var k int
 	if height <= 0 {
-		return nil, nil
+		return nil
 	}
 	eis := s.EditedIndices
 	for 0 < len(eis) && eis[1] <= s.Offset {
 		eis = eis[2:]
 	}
-	bytes := make([][]byte, height)
-	styles := make([][]tcell.Style, height)
-	color := tcell.ColorLightSeaGreen
+	cells := make([][]cell, height)
 	cursorPos := int(s.Cursor - s.Offset)
 	for i := 0; i < height; i++ {
-		bytes[i] = make([]byte, width)
-		styles[i] = make([]tcell.Style, width)
+		cells[i] = make([]cell, width)
 		for j := 0; j < width; j++ {
+			c := &cells[i][j]
 			if s.Pending && i*width+j == cursorPos {
-				bytes[i][j] = s.PendingByte
-				styles[i][j] = styles[i][j].Foreground(color)
+				c.b = s.PendingByte
+				c.highlight = ui.highlights[highlight.Edited]
 				if s.Mode == mode.Replace {
 					k++
 				}
 				continue
 			}
 			if k >= s.Size {
-				if k == cursorPos {
-					styles[i][j] = tcell.Style(math.MaxInt16 - 1)
-				} else {
-					styles[i][j] = tcell.Style(math.MaxInt16)
-				}
+				c.eof = true
 				k++
 				continue
 			}
-			bytes[i][j] = s.Bytes[k]
+			c.b = s.Bytes[k]
 			pos := int64(k) + s.Offset
 			if 0 < len(eis) && eis[0] <= pos && pos < eis[1] {
-				styles[i][j] = styles[i][j].Foreground(color)
+				c.highlight = ui.highlights[highlight.Edited]
 			} else if 0 < len(eis) && eis[1] <= pos {
 				eis = eis[2:]
 			}
 			if s.VisualStart >= 0 && s.Cursor < s.Length &&
 				(s.VisualStart <= pos && pos <= s.Cursor ||
 					s.Cursor <= pos && pos <= s.VisualStart) {
-				styles[i][j] = styles[i][j].Underline(true)
+				c.highlight = c.highlight.Merge(ui.highlights[highlight.Visual])
+			}
+			if s.MatchStart <= pos && pos < s.MatchEnd {
+				c.highlight = c.highlight.Merge(ui.highlights[highlight.Search])
 			}
 			k++
 		}
 	}
-	return bytes, styles
+	ui.decodeText(cells, width, s.Offset)
+	return cells
 }
 
-func (ui *tuiWindow) drawHeader(s *state.WindowState, offsetStyleWidth int) {
-	style := tcell.StyleDefault.Underline(true)
+// decodeText sets the characters of the text column decoded in the encoding.
+// The character is drawn at the cell of the first byte and the following
+// cells of the character are blank. The characters not fitting in the line
+// and the bytes which cannot be decoded are drawn as dots.
+func (ui *tuiWindow) decodeText(cells [][]cell, width int, offset int64) {
+	var bs []byte
+	for _, row := range cells {
+		for _, c := range row {
+			if c.eof {
+				break
+			}
+			bs = append(bs, c.b)
+		}
+	}
+	encoding := ui.options.Encoding
+	i := int(offset % int64(charset.UnitSize(encoding)))
+	for j := 0; j < i && j < len(bs); j++ {
+		cells[j/width][j%width].text = "."
+	}
+	for i < len(bs) {
+		r, n := charset.Decode(encoding, bs[i:mathutil.MinInt(i+4, len(bs))])
+		w := runewidth.RuneWidth(r)
+		c := &cells[i/width][i%width]
+		if r == utf8.RuneError || !unicode.IsPrint(r) || w == 0 || n < w || width-i%width < w {
+			c.text, w = ".", 1
+		} else {
+			c.text = string(r)
+		}
+		for j := i + 1; j < i+n && j < len(bs); j++ {
+			if j-i >= w {
+				cells[j/width][j%width].text = " "
+			}
+		}
+		i += n
+	}
+}
+
+func (ui *tuiWindow) drawHeader(s *state.WindowState, left int) {
+	style := ui.style(highlight.Header)
+	disp := getDisplay(s)
+	hexWidth := disp.Width(s.Width)
 	d := ui.getTextDrawer()
-	d.setString(strings.Repeat(" ", 4*s.Width+8+offsetStyleWidth), style)
-	d.setLeft(offsetStyleWidth)
+	d.setString(strings.Repeat(" ", left+hexWidth+s.Width+5), style)
+	d.setLeft(left)
 	cursor := int(s.Cursor % int64(s.Width))
 	for i := 0; i < s.Width; i++ {
-		d.setOffset(3*i+4).setString(fmt.Sprintf("%2x", i), style.Bold(cursor == i))
+		offset := disp.Position(i) + disp.Digits() - 2
+		if cursor == i {
+			d.setOffset(offset).setString(fmt.Sprintf("%2x", i),
+				ui.style(highlight.Header, highlight.CursorOffset))
+		} else {
+			d.setOffset(offset).setString(fmt.Sprintf("%2x", i), style)
+		}
+	}
+	if left > 0 {
+		d.setOffset(-1).setString("|", style)
 	}
-	d.setOffset(2).setString("|", style)
-	d.setOffset(3*s.Width+4).setString("|", style)
+	d.setOffset(hexWidth+1).setString("|", style)
 }
 
 func (ui *tuiWindow) drawScrollBar(s *state.WindowState, height int, left int) {
@@ -163,18 +303,18 @@ func (ui *tuiWindow) drawScrollBar(s *state.WindowState, height int, left int) {
 	pad := (total*total + len - len*size - 1) / mathutil.MaxInt64(total-size+1, 1)
 	top := (s.Offset / int64(s.Width) * total) / (len - pad)
 	d := ui.getTextDrawer().setLeft(left)
+	style := ui.style(highlight.ScrollBar)
 	for i := 0; i < height; i++ {
 		d.setTop(i + 1)
 		if int(top) <= i && i < int(top+size) {
-			d.setString("#", tcell.StyleDefault)
+			d.setString("#", style)
 		} else {
-			d.setString("|", tcell.StyleDefault)
+			d.setString("|", style)
 		}
 	}
 }
 
-func (ui *tuiWindow) drawFooter(s *state.WindowState, offsetStyleWidth int) {
-	offsetStyle := "0x%0" + strconv.Itoa(offsetStyleWidth) + "x"
+func (ui *tuiWindow) drawFooter(s *state.WindowState, offsetStyleWidth int, active bool) {
 	j := int(s.Cursor - s.Offset)
 	name := s.Name
 	if name == "" {
@@ -186,21 +326,38 @@ func (ui *tuiWindow) drawFooter(s *state.WindowState, offsetStyleWidth int) {
 	}
 	left := fmt.Sprintf(" %s%s%s : 0x%02x : '%s'",
 		prettyMode(s.Mode), name, modified, s.Bytes[j], prettyRune(s.Bytes[j]))
-	right := fmt.Sprintf("%d/%d : "+offsetStyle+"/"+offsetStyle+" : %.2f%% ",
-		s.Cursor, s.Length, s.Cursor, s.Length,
+	if s.Searching {
+		left += fmt.Sprintf(" : searching %d%%", s.SearchProgress)
+	}
+	right := fmt.Sprintf("%d/%d : %s/%s : %.2f%% ",
+		s.Cursor, s.Length,
+		ui.formatOffset(s.Cursor, offsetStyleWidth, true),
+		ui.formatOffset(s.Length, offsetStyleWidth, true),
 		float64(s.Cursor*100)/float64(mathutil.MaxInt64(s.Length, 1)))
 	line := left + strings.Repeat(
 		" ", mathutil.MaxInt(2, ui.region.width-len(left)-len(right)),
 	) + right
-	ui.getTextDrawer().setTop(ui.region.height-1).setString(line, tcell.StyleDefault.Reverse(true))
+	style := ui.style(highlight.StatusLine)
+	if !active {
+		style = ui.style(highlight.StatusLineNC)
+	}
+	ui.getTextDrawer().setTop(ui.region.height-1).setString(line, style)
 }
 
-func prettyByte(b byte) byte {
+func byteHighlight(b byte) string {
 	switch {
-	case 0x20 <= b && b < 0x7f:
-		return b
+	case b == 0x00:
+		return highlight.NullByte
+	case b == 0xff:
+		return highlight.FFByte
+	case b == 0x20 || 0x09 <= b && b <= 0x0d:
+		return highlight.AsciiWhitespace
+	case 0x20 < b && b < 0x7f:
+		return highlight.AsciiPrintable
+	case b < 0x80:
+		return highlight.AsciiOther
 	default:
-		return 0x2e
+		return highlight.NonAscii
 	}
 }
 
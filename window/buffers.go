@@ -0,0 +1,301 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/mathutil"
+)
+
+// listBuffers shows the buffers with the flags; % for the current buffer,
+// # for the alternate buffer, a for the visible buffers, h for the hidden
+// buffers and + for the modified buffers.
+func (m *Manager) listBuffers(e event.Event) error {
+	if len(e.Arg) > 0 {
+		return fmt.Errorf("too many arguments for %s", e.CmdName)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	visible := m.layout.Collect()
+	var lines []string
+	for i, window := range m.windows {
+		if window == nil {
+			continue
+		}
+		flags := []byte("   ")
+		if i == m.windowIndex {
+			flags[0] = '%'
+		} else if i == m.prevWindowIndex {
+			flags[0] = '#'
+		}
+		if _, ok := visible[i]; ok {
+			flags[1] = 'a'
+		} else {
+			flags[1] = 'h'
+		}
+		window.mu.Lock()
+		if window.changedTick != window.savedChangedTick {
+			flags[2] = '+'
+		}
+		name, length := window.filename, window.length
+		window.mu.Unlock()
+		if name == "" {
+			name = "[No name]"
+		}
+		lines = append(lines, fmt.Sprintf("%3d %s \"%s\" %d (0x%x) bytes", i+1, flags, name, length, length))
+	}
+	m.eventCh <- event.Event{Type: event.Info, Error: errors.New(strings.Join(lines, "\n"))}
+	return nil
+}
+
+// BufferNames returns the names of the buffers for completion.
+func (m *Manager) BufferNames() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for _, window := range m.windows {
+		if window != nil && window.filename != "" {
+			names = append(names, window.filename)
+		}
+	}
+	return names
+}
+
+// switchBuffer switches the current window to the buffer of the number
+// or the name. The name matches partially if there is no exact match.
+func (m *Manager) switchBuffer(e event.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	arg := strings.TrimSpace(e.Arg)
+	if arg == "" {
+		return nil
+	}
+	index, err := m.findBuffer(arg)
+	if err != nil {
+		return err
+	}
+	m.windowIndex, m.prevWindowIndex = index, m.windowIndex
+	m.layout = m.layout.Replace(m.windowIndex)
+	return nil
+}
+
+// jumpTo moves the cursor of the window to the offset. The current window
+// is switched to the window, in the layout if it is visible.
+func (m *Manager) jumpTo(window *window, offset int64) error {
+	index := -1
+	for i, w := range m.windows {
+		if w == window {
+			index = i
+		}
+	}
+	if index < 0 {
+		return errors.New("buffer is deleted")
+	}
+	if index != m.windowIndex {
+		if _, ok := m.layout.Collect()[index]; ok {
+			m.layout = m.layout.Activate(index)
+		} else {
+			m.layout = m.layout.Replace(index)
+		}
+		m.windowIndex, m.prevWindowIndex = index, m.windowIndex
+	}
+	window.mu.Lock()
+	defer window.mu.Unlock()
+	window.cursor = mathutil.MaxInt64(0, mathutil.MinInt64(offset, window.length-1))
+	return nil
+}
+
+func (m *Manager) findBuffer(arg string) (int, error) {
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n <= 0 || len(m.windows) < n || m.windows[n-1] == nil {
+			return 0, fmt.Errorf("buffer %d does not exist", n)
+		}
+		return n - 1, nil
+	}
+	index := -1
+	for i, window := range m.windows {
+		if window == nil {
+			continue
+		}
+		if window.filename == arg || window.name == arg {
+			return i, nil
+		}
+		if strings.Contains(window.filename, arg) {
+			if index >= 0 {
+				return 0, fmt.Errorf("more than one match for %s", arg)
+			}
+			index = i
+		}
+	}
+	if index < 0 {
+		return 0, fmt.Errorf("no matching buffer for %s", arg)
+	}
+	return index, nil
+}
+
+// nextBuffer switches the current window to the next or previous buffer
+// count times, skipping the deleted buffers.
+func (m *Manager) nextBuffer(e event.Event, forward bool) error {
+	if e.Arg != "" {
+		n, err := strconv.ParseInt(e.Arg, 10, 64)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid argument for %s: %s", e.CmdName, e.Arg)
+		}
+		e.Count = n
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	index := m.windowIndex
+	for count := mathutil.MaxInt64(e.Count, 1); count > 0; {
+		if forward {
+			index = (index + 1) % len(m.windows)
+		} else {
+			index = (index + len(m.windows) - 1) % len(m.windows)
+		}
+		if m.windows[index] != nil {
+			count--
+		}
+	}
+	if index != m.windowIndex {
+		m.windowIndex, m.prevWindowIndex = index, m.windowIndex
+		m.layout = m.layout.Replace(m.windowIndex)
+	}
+	return nil
+}
+
+// deleteBuffer deletes the buffer and closes the files read by the buffer.
+// The windows showing the buffer are replaced with the alternate buffer.
+// The deleted window is left as nil to keep the numbers of the buffers.
+func (m *Manager) deleteBuffer(e event.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	index := m.windowIndex
+	if arg := strings.TrimSpace(e.Arg); arg != "" {
+		var err error
+		if index, err = m.findBuffer(arg); err != nil {
+			return err
+		}
+	}
+	window := m.windows[index]
+	if window.changedTick != window.savedChangedTick && !e.Bang {
+		return fmt.Errorf("buffer %d has unsaved changes, use %s! to force delete", index+1, e.CmdName)
+	}
+	alternate := m.alternateBuffer(index)
+	if alternate < 0 {
+		w, err := m.open("")
+		if err != nil {
+			return err
+		}
+		m.windows = append(m.windows, w)
+		alternate = len(m.windows) - 1
+	}
+	if window.filterCancel != nil {
+		window.filterCancel()
+	}
+	if window.hashCancel != nil {
+		window.hashCancel()
+	}
+	if m.quickfix.window == window {
+		m.quickfix.abort()
+		if m.quickfix.open {
+			defer func() { m.layout = m.resizeLayout(m.layout) }()
+		}
+		m.quickfix = newQuickfix()
+	}
+	for _, w := range m.allSearchWindows {
+		if w == window {
+			close(m.allSearchQuit)
+			m.allSearchQuit, m.allSearchWindows = nil, nil
+			break
+		}
+	}
+	m.windows[index] = nil
+	m.closeFiles()
+	for i, t := range m.tabs {
+		if i != m.tabIndex {
+			m.tabs[i] = m.replaceBuffer(t, index, alternate)
+		}
+	}
+	t := m.replaceBuffer(tab{m.layout, m.windowIndex, m.prevWindowIndex}, index, alternate)
+	m.layout, m.windowIndex, m.prevWindowIndex = t.layout, t.windowIndex, t.prevWindowIndex
+	m.eventCh <- event.Event{Type: event.Info, Error: fmt.Errorf("buffer %d deleted", index+1)}
+	return nil
+}
+
+// alternateBuffer returns the buffer to be shown instead of the buffer,
+// or -1 if there is no other buffer.
+func (m *Manager) alternateBuffer(index int) int {
+	if i := m.prevWindowIndex; i != index && m.windows[i] != nil {
+		return i
+	}
+	for i := 1; i < len(m.windows); i++ {
+		if j := (index + i) % len(m.windows); m.windows[j] != nil {
+			return j
+		}
+	}
+	return -1
+}
+
+func (m *Manager) replaceBuffer(t tab, index, alternate int) tab {
+	if _, ok := t.layout.Collect()[index]; ok {
+		active := t.layout.ActiveWindow().Index
+		t.layout = t.layout.Activate(index).Replace(alternate)
+		if active == index {
+			active = alternate
+		}
+		t.layout = t.layout.Activate(active)
+	}
+	if t.windowIndex == index {
+		t.windowIndex = alternate
+	}
+	if t.prevWindowIndex == index || m.windows[t.prevWindowIndex] == nil {
+		t.prevWindowIndex = t.windowIndex
+	}
+	return t
+}
+
+// closeFiles closes the files read by the deleted windows. The files still
+// referred by the other windows or the register are kept open since the
+// buffers read them lazily.
+func (m *Manager) closeFiles() {
+	files := m.files[:0]
+	for _, f := range m.files {
+		if !m.alive(f.window) && !m.referred(f.reader) {
+			f.file.Close()
+			continue
+		}
+		files = append(files, f)
+	}
+	m.files = files
+}
+
+func (m *Manager) alive(window *window) bool {
+	for _, w := range m.windows {
+		if w == window {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) referred(r io.ReaderAt) bool {
+	if m.register != nil && m.register.Refers(r) {
+		return true
+	}
+	for _, window := range m.windows {
+		if window == nil {
+			continue
+		}
+		window.mu.Lock()
+		ok := window.buffer.Refers(r) || window.history.Refers(r)
+		window.mu.Unlock()
+		if ok {
+			return true
+		}
+	}
+	return false
+}
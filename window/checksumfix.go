@@ -0,0 +1,232 @@
+package window
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/itchyny/bed/buffer"
+	"github.com/itchyny/bed/checksum"
+	"github.com/itchyny/bed/event"
+)
+
+// checksumField is the field to store the checksum of the data range.
+// The offsets are absolute in the buffer and the end is exclusive.
+type checksumField struct {
+	name      string
+	from, to  int64
+	store     int64
+	width     int
+	bigEndian bool
+}
+
+// checksumFix stores the checksum of the data range in the field, or updates
+// all the fields of the window without the argument.
+func (w *window) checksumFix(e event.Event) (string, error) {
+	if e.Range != nil {
+		return "", fmt.Errorf("range not allowed for %s", e.CmdName)
+	}
+	if strings.TrimSpace(e.Arg) == "" {
+		if len(w.checksumFields) == 0 {
+			return "", errors.New("no checksum field")
+		}
+		return w.updateChecksums()
+	}
+	field, err := w.parseChecksumField(e)
+	if err != nil {
+		return "", err
+	}
+	msg, err := w.updateChecksum(field)
+	if err != nil {
+		return "", err
+	}
+	for i, f := range w.checksumFields {
+		if f.store == field.store {
+			w.checksumFields[i] = field
+			return msg, nil
+		}
+	}
+	w.checksumFields = append(w.checksumFields, field)
+	return msg, nil
+}
+
+// parseChecksumField parses the argument of the checksum-fix command;
+// {algorithm} data={range} store={pos} [le|be] [width=N].
+func (w *window) parseChecksumField(e event.Event) (*checksumField, error) {
+	args := strings.Fields(e.Arg)
+	h, err := checksum.New(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid argument for %s: %s", e.CmdName, args[0])
+	}
+	field := &checksumField{name: args[0], from: -1, store: -1, width: h.Size(), bigEndian: true}
+	for _, arg := range args[1:] {
+		switch {
+		case arg == "le" || arg == "be":
+			field.bigEndian = arg == "be"
+		case strings.HasPrefix(arg, "data="):
+			xs := []rune(strings.TrimPrefix(arg, "data="))
+			r, i := event.ParseRange(xs, 0)
+			if r == nil || r.To == nil || i != len(xs) {
+				return nil, fmt.Errorf("invalid argument for %s: %s", e.CmdName, arg)
+			}
+			if field.from, err = w.checksumOffset(r.From); err != nil {
+				return nil, err
+			}
+			if field.to, err = w.checksumOffset(r.To); err != nil {
+				return nil, err
+			}
+			if field.from > field.to {
+				field.from, field.to = field.to, field.from
+			}
+			field.to++
+		case strings.HasPrefix(arg, "store="):
+			xs := []rune(strings.TrimPrefix(arg, "store="))
+			pos, i := event.ParsePos(xs, 0)
+			if pos == nil || i != len(xs) {
+				return nil, fmt.Errorf("invalid argument for %s: %s", e.CmdName, arg)
+			}
+			if field.store, err = w.checksumOffset(pos); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(arg, "width="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "width="))
+			if err != nil || n <= 0 || n > 64 {
+				return nil, fmt.Errorf("invalid argument for %s: %s", e.CmdName, arg)
+			}
+			field.width = n
+		default:
+			return nil, fmt.Errorf("invalid argument for %s: %s", e.CmdName, arg)
+		}
+	}
+	if field.from < 0 {
+		return nil, fmt.Errorf("data range is required for %s", e.CmdName)
+	}
+	if field.store < 0 {
+		return nil, fmt.Errorf("store offset is required for %s", e.CmdName)
+	}
+	return field, nil
+}
+
+// checksumOffset converts the position to the offset in the buffer. Unlike
+// positionToOffset, the offset is not clamped to the buffer.
+func (w *window) checksumOffset(pos event.Position) (int64, error) {
+	var offset int64
+	switch pos := pos.(type) {
+	case event.Absolute:
+		offset = pos.Offset - w.offsetOrigin
+	case event.Relative:
+		offset = w.cursor + pos.Offset
+	case event.End:
+		offset = w.length - 1 + pos.Offset
+	case event.VisualStart:
+		if w.visualStart < 0 {
+			return 0, errors.New("no visual selection found")
+		}
+		offset = w.visualStart + pos.Offset
+	case event.VisualEnd:
+		if w.visualStart < 0 {
+			return 0, errors.New("no visual selection found")
+		}
+		offset = w.cursor + pos.Offset
+	case event.Expression:
+		value, err := pos.Eval(exprEnv{w})
+		if err != nil {
+			return 0, err
+		}
+		offset = value - w.offsetOrigin
+	default:
+		return 0, errors.New("invalid range")
+	}
+	if offset < 0 || w.length <= offset {
+		return 0, fmt.Errorf("checksum field out of range: 0x%x", offset+w.offsetOrigin)
+	}
+	return offset, nil
+}
+
+// updateChecksums updates all the checksum fields of the window. All the
+// fields are checked before updating, and the buffer is restored on failure.
+func (w *window) updateChecksums() (string, error) {
+	for _, field := range w.checksumFields {
+		if err := w.checkChecksumField(field); err != nil {
+			return "", err
+		}
+	}
+	buf, changedTick := w.buffer.Clone(), w.changedTick
+	for _, field := range w.checksumFields {
+		if _, err := w.updateChecksum(field); err != nil {
+			w.buffer, w.changedTick = buf, changedTick
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%d checksum fields updated", len(w.checksumFields)), nil
+}
+
+// checkChecksumField checks the field is in the buffer and does not overlap
+// the data range.
+func (w *window) checkChecksumField(field *checksumField) error {
+	if field.to > w.length || field.store+int64(field.width) > w.length {
+		return fmt.Errorf("checksum field out of range: 0x%x", field.store+w.offsetOrigin)
+	}
+	if field.store < field.to && field.from < field.store+int64(field.width) {
+		return errors.New("checksum field overlaps the data range")
+	}
+	return nil
+}
+
+// updateChecksum computes the checksum of the data range and overwrites the
+// field with it. The checksum is truncated to the lower bytes or extended
+// with zeros to the width of the field.
+func (w *window) updateChecksum(field *checksumField) (string, error) {
+	if err := w.checkChecksumField(field); err != nil {
+		return "", err
+	}
+	h, err := checksum.New(field.name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, io.NewSectionReader(w.buffer, field.from, field.to-field.from)); err != nil {
+		return "", err
+	}
+	sum := h.Sum(nil)
+	bs := make([]byte, field.width)
+	for i := 0; i < len(bs) && i < len(sum); i++ {
+		bs[len(bs)-1-i] = sum[len(sum)-1-i]
+	}
+	value := fmt.Sprintf("%x", bs)
+	if !field.bigEndian {
+		for i, j := 0, len(bs)-1; i < j; i, j = i+1, j-1 {
+			bs[i], bs[j] = bs[j], bs[i]
+		}
+	}
+	_, prev, err := w.readBytes(field.store, field.width)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(prev, bs) {
+		w.buffer.Overwrite(field.store, buffer.NewBuffer(bytes.NewReader(bs)))
+		w.updateTick()
+	}
+	endian := "le"
+	if field.bigEndian {
+		endian = "be"
+	}
+	return fmt.Sprintf("%s: %s (%s) stored at 0x%x", field.name, value, endian, field.store+w.offsetOrigin), nil
+}
+
+// fixChecksums updates the checksum fields before writing the buffer, as an
+// undoable change.
+func (w *window) fixChecksums() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	changedTick := w.changedTick
+	if _, err := w.updateChecksums(); err != nil {
+		return err
+	}
+	if w.changedTick != changedTick {
+		w.history.Push(w.buffer, w.offset, w.cursor, w.changedTick)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/itchyny/bed/mathutil"
+)
+
+// exprEnv implements event.Env to evaluate expressions on the window.
+// The offsets in the expressions are the addresses with the offset origin.
+type exprEnv struct {
+	w *window
+}
+
+func (env exprEnv) ReadAt(p []byte, address int64) (int, error) {
+	offset := address - env.w.offsetOrigin
+	if offset < 0 || offset >= env.w.length {
+		return 0, fmt.Errorf("offset out of range: 0x%x", address)
+	}
+	return env.w.buffer.ReadAt(p[:mathutil.MinInt64(int64(len(p)), env.w.length-offset)], offset)
+}
+
+func (env exprEnv) Cursor() int64 {
+	return env.w.offsetOrigin + env.w.cursor
+}
+
+func (env exprEnv) End() int64 {
+	return env.w.offsetOrigin + mathutil.MaxInt64(env.w.length, 1) - 1
+}
+
+func (env exprEnv) Mark(name rune) (int64, error) {
+	switch name {
+	case '<':
+		if env.w.visualStart < 0 {
+			return 0, errors.New("no visual selection found")
+		}
+		return env.w.offsetOrigin + env.w.visualStart, nil
+	case '>':
+		if env.w.visualStart < 0 {
+			return 0, errors.New("no visual selection found")
+		}
+		return env.w.offsetOrigin + env.w.cursor, nil
+	}
+	if offset, ok := env.w.marks[name]; ok {
+		return env.w.offsetOrigin + offset, nil
+	}
+	return 0, fmt.Errorf("mark not set: %c", name)
+}
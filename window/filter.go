@@ -0,0 +1,115 @@
+package window
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/itchyny/bed/buffer"
+	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/mathutil"
+	"github.com/itchyny/bed/mode"
+)
+
+// filter runs the command in background. If the range is specified,
+// the bytes in the range are passed to the command and replaced with
+// the output. Otherwise the output of the command is shown.
+func (w *window) filter(e event.Event) error {
+	if e.Arg == "" {
+		return fmt.Errorf("an argument is required for %s", e.CmdName)
+	}
+	if w.filterCancel != nil {
+		return errors.New("another command is running")
+	}
+	var stdin io.Reader
+	var from, to int64
+	if e.Range != nil {
+		var err error
+		if from, err = w.positionToOffset(e.Range.From); err != nil {
+			return err
+		}
+		to = from
+		if e.Range.To != nil {
+			if to, err = w.positionToOffset(e.Range.To); err != nil {
+				return err
+			}
+		}
+		if from > to {
+			from, to = to, from
+		}
+		stdin = w.buffer.Copy(from, mathutil.MinInt64(to+1, w.length))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := shellCommand(ctx, e.Arg)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, &stdout, &stderr
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return err
+	}
+	w.filterCancel, w.filterTick = cancel, w.changedTick
+	go func() {
+		err := cmd.Wait()
+		w.mu.Lock()
+		w.filterCancel = nil
+		w.mu.Unlock()
+		if ctx.Err() != nil {
+			w.eventCh <- event.Event{Type: event.Info, Error: errors.New("command is aborted")}
+			return
+		}
+		cancel()
+		if err != nil {
+			if s := strings.TrimSpace(stderr.String()); s != "" {
+				err = fmt.Errorf("%s: %s", e.Arg, s)
+			}
+			w.eventCh <- event.Event{Type: event.Error, Error: err}
+			return
+		}
+		if e.Range == nil {
+			w.eventCh <- event.Event{Type: event.Info, Error: errors.New(strings.TrimSpace(stdout.String()))}
+			return
+		}
+		w.emit(event.Event{
+			Type:   event.Filtered,
+			Range:  &event.Range{From: event.Absolute{Offset: from}, To: event.Absolute{Offset: to}},
+			Mode:   mode.Normal,
+			Buffer: buffer.NewBuffer(bytes.NewReader(stdout.Bytes())),
+		})
+	}()
+	return nil
+}
+
+// filtered replaces the range with the output of the filter command.
+func (w *window) filtered(e event.Event) (int64, error) {
+	if w.filterTick != w.changedTick {
+		return 0, errors.New("buffer is changed while running the command")
+	}
+	from := e.Range.From.(event.Absolute).Offset
+	to := e.Range.To.(event.Absolute).Offset
+	if to < w.length {
+		w.buffer.Cut(from, to+1)
+	}
+	w.buffer.Paste(from, e.Buffer)
+	l, _ := e.Buffer.Len()
+	w.length, _ = w.buffer.Len()
+	w.cursor = mathutil.MinInt64(from, mathutil.MaxInt64(w.length, 1)-1)
+	w.updateTick()
+	return l, nil
+}
+
+func shellCommand(ctx context.Context, arg string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/c", arg)
+	}
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+	return exec.CommandContext(ctx, shell, "-c", arg)
+}
@@ -0,0 +1,86 @@
+package window
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/itchyny/bed/buffer"
+	"github.com/itchyny/bed/checksum"
+	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/mathutil"
+)
+
+// hash computes the digest of the bytes in the range, or the whole buffer,
+// in background. The digest is stored in the register with the bang, without
+// changing the mode.
+func (w *window) hash(e event.Event) error {
+	name := strings.TrimSpace(e.Arg)
+	if name == "" {
+		return fmt.Errorf("an argument is required for %s", e.CmdName)
+	}
+	h, err := checksum.New(name)
+	if err != nil {
+		return fmt.Errorf("invalid argument for %s: %s", e.CmdName, name)
+	}
+	if w.hashCancel != nil {
+		return errors.New("another command is running")
+	}
+	from, to := int64(0), w.length
+	if e.Range != nil {
+		if from, err = w.positionToOffset(e.Range.From); err != nil {
+			return err
+		}
+		to = from
+		if e.Range.To != nil {
+			if to, err = w.positionToOffset(e.Range.To); err != nil {
+				return err
+			}
+		}
+		if from > to {
+			from, to = to, from
+		}
+		to = mathutil.MinInt64(to+1, w.length)
+	}
+	r := io.NewSectionReader(w.buffer.Clone(), from, mathutil.MaxInt64(to-from, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	w.hashCancel = cancel
+	go func() {
+		defer cancel()
+		_, err := io.CopyBuffer(h, contextReader{ctx, r}, make([]byte, 64*1024))
+		w.mu.Lock()
+		w.hashCancel = nil
+		w.mu.Unlock()
+		if ctx.Err() != nil {
+			w.eventCh <- event.Event{Type: event.Info, Error: errors.New("command is aborted")}
+			return
+		}
+		if err != nil {
+			w.eventCh <- event.Event{Type: event.Error, Error: err}
+			return
+		}
+		sum := h.Sum(nil)
+		ev := event.Event{Type: event.Hashed, Error: fmt.Errorf("%s: %x", name, sum)}
+		if e.Bang {
+			ev.Buffer = buffer.NewBuffer(bytes.NewReader(sum))
+		}
+		w.eventCh <- ev
+	}()
+	return nil
+}
+
+// contextReader stops reading when the context is canceled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
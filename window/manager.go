@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"os/exec"
@@ -13,30 +14,52 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/itchyny/bed/buffer"
+	"github.com/itchyny/bed/display"
 	"github.com/itchyny/bed/event"
 	"github.com/itchyny/bed/layout"
 	"github.com/itchyny/bed/mathutil"
+	"github.com/itchyny/bed/option"
+	"github.com/itchyny/bed/pathutil"
 	"github.com/itchyny/bed/state"
 )
 
 // Manager manages the windows and files.
 type Manager struct {
-	width           int
-	height          int
-	windows         []*window
+	width            int
+	height           int
+	windows          []*window
+	layout           layout.Layout
+	mu               *sync.Mutex
+	windowIndex      int
+	prevWindowIndex  int
+	tabs             []tab
+	tabIndex         int
+	files            []file
+	quickfix         *quickfix
+	allSearch        bool
+	allSearchQuit    chan struct{}
+	allSearchWindows []*window
+	options          option.Options
+	register         *buffer.Buffer
+	eventCh          chan<- event.Event
+	redrawCh         chan<- struct{}
+}
+
+// tab holds the layout of a tab page. The current tab page is held by
+// the fields of the Manager and saved to the tabs on switching tab pages.
+type tab struct {
 	layout          layout.Layout
-	mu              *sync.Mutex
 	windowIndex     int
 	prevWindowIndex int
-	files           []file
-	eventCh         chan<- event.Event
-	redrawCh        chan<- struct{}
 }
 
 type file struct {
-	name string
-	file *os.File
-	perm os.FileMode
+	name   string
+	file   *os.File
+	reader io.ReaderAt
+	perm   os.FileMode
+	window *window
 }
 
 // NewManager creates a new Manager.
@@ -48,6 +71,36 @@ func NewManager() *Manager {
 func (m *Manager) Init(eventCh chan<- event.Event, redrawCh chan<- struct{}) {
 	m.eventCh, m.redrawCh = eventCh, redrawCh
 	m.mu = new(sync.Mutex)
+	m.options = option.Default()
+	m.quickfix = newQuickfix()
+}
+
+// SetOptions sets the options to the windows.
+func (m *Manager) SetOptions(options option.Options) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.options = options
+	for _, window := range m.windows {
+		if window != nil {
+			window.setOptions(options)
+		}
+	}
+}
+
+// Options returns the options, which are restored by OpenSession.
+func (m *Manager) Options() option.Options {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.options
+}
+
+// SetRegister sets the buffer of the register, which keeps the files referred
+// by the buffer open after the windows are deleted.
+func (m *Manager) SetRegister(register *buffer.Buffer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.register = register
+	m.closeFiles()
 }
 
 // Open a new window.
@@ -59,7 +112,10 @@ func (m *Manager) Open(filename string) error {
 		return err
 	}
 	m.addWindow(window)
-	m.layout = layout.NewLayout(m.windowIndex).Resize(0, 0, m.width, m.height)
+	if len(m.tabs) == 0 {
+		m.tabs = make([]tab, 1)
+	}
+	m.layout = m.resizeLayout(layout.NewLayout(m.windowIndex))
 	return nil
 }
 
@@ -76,18 +132,14 @@ func (m *Manager) addWindow(window *window) {
 
 func (m *Manager) open(filename string) (*window, error) {
 	if filename == "" {
-		window, err := newWindow(bytes.NewReader(nil), "", "", m.eventCh, m.redrawCh)
-		if err != nil {
-			return nil, err
-		}
-		return window, nil
+		return m.createWindow(bytes.NewReader(nil), "", "")
 	}
 	if filename == "#" {
 		return m.windows[m.prevWindowIndex], nil
 	}
 	if strings.HasPrefix(filename, "#") {
 		index, err := strconv.Atoi(filename[1:])
-		if err != nil || index <= 0 || len(m.windows) < index {
+		if err != nil || index <= 0 || len(m.windows) < index || m.windows[index-1] == nil {
 			return nil, fmt.Errorf("invalid window index: %s", filename)
 		}
 		return m.windows[index-1], nil
@@ -96,7 +148,7 @@ func (m *Manager) open(filename string) (*window, error) {
 	if err != nil {
 		return nil, err
 	}
-	name, err = homedirExpand(name)
+	name, err = pathutil.HomedirExpand(name)
 	if err != nil {
 		return nil, err
 	}
@@ -106,11 +158,7 @@ func (m *Manager) open(filename string) (*window, error) {
 		if !os.IsNotExist(err) {
 			return nil, err
 		}
-		window, err := newWindow(bytes.NewReader(nil), filename, filepath.Base(filename), m.eventCh, m.redrawCh)
-		if err != nil {
-			return nil, err
-		}
-		return window, nil
+		return m.createWindow(bytes.NewReader(nil), filename, filepath.Base(filename))
 	}
 	info, err := os.Stat(filename)
 	if err != nil {
@@ -119,11 +167,21 @@ func (m *Manager) open(filename string) (*window, error) {
 	if info.IsDir() {
 		return nil, fmt.Errorf("%s is a directory", filename)
 	}
-	m.files = append(m.files, file{name: filename, file: f, perm: info.Mode().Perm()})
-	window, err := newWindow(f, filename, filepath.Base(filename), m.eventCh, m.redrawCh)
+	window, err := m.createWindow(f, filename, filepath.Base(filename))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	m.files = append(m.files, file{name: filename, file: f, reader: f, perm: info.Mode().Perm(), window: window})
+	return window, nil
+}
+
+func (m *Manager) createWindow(r readAtSeeker, filename, name string) (*window, error) {
+	window, err := newWindow(r, filename, name, m.eventCh, m.redrawCh)
 	if err != nil {
 		return nil, err
 	}
+	window.setOptions(m.options)
 	return window, nil
 }
 
@@ -155,12 +213,15 @@ func (m *Manager) Resize(width, height int) {
 		m.mu.Lock()
 		defer m.mu.Unlock()
 		m.width, m.height = width, height
-		m.layout = m.layout.Resize(0, 0, width, height)
+		m.layout = m.resizeLayout(m.layout)
 	}
 }
 
 // Emit an event to the current window.
 func (m *Manager) Emit(e event.Event) {
+	if m.emitQuickfix(e) {
+		return
+	}
 	switch e.Type {
 	case event.Edit:
 		if err := m.edit(e); err != nil {
@@ -189,6 +250,30 @@ func (m *Manager) Emit(e event.Event) {
 	case event.Alternative:
 		m.alternative(e)
 		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.Buffers:
+		if err := m.listBuffers(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		}
+	case event.Buffer:
+		if err := m.switchBuffer(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		} else {
+			m.eventCh <- event.Event{Type: event.Redraw}
+		}
+	case event.BufferNext, event.BufferPrevious:
+		if err := m.nextBuffer(e, e.Type == event.BufferNext); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		} else {
+			m.eventCh <- event.Event{Type: event.Redraw}
+		}
+	case event.BufferDelete:
+		if err := m.deleteBuffer(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		}
+	case event.MakeSession:
+		if err := m.mksession(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		}
 	case event.Wincmd:
 		if len(e.Arg) == 0 {
 			m.eventCh <- event.Event{Type: event.Error, Error: fmt.Errorf("an argument is required for %s", e.CmdName)}
@@ -263,6 +348,80 @@ func (m *Manager) Emit(e event.Event) {
 		} else {
 			m.eventCh <- event.Event{Type: event.Redraw}
 		}
+	case event.Read:
+		if err := m.read(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		}
+	case event.RotateWindowsDownwards:
+		m.transform("r", e.Count)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.RotateWindowsUpwards:
+		m.transform("R", e.Count)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.ExchangeWindow:
+		m.transform("x", e.Count)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.OnlyWindow:
+		if len(e.Arg) > 0 {
+			m.eventCh <- event.Event{Type: event.Error, Error: fmt.Errorf("too many arguments for %s", e.CmdName)}
+		} else {
+			m.transform("o", e.Count)
+			m.eventCh <- event.Event{Type: event.Redraw}
+		}
+	case event.IncreaseWindowHeight:
+		m.resizeWindow("+", e.Count)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.DecreaseWindowHeight:
+		m.resizeWindow("-", e.Count)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.IncreaseWindowWidth:
+		m.resizeWindow(">", e.Count)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.DecreaseWindowWidth:
+		m.resizeWindow("<", e.Count)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.EqualizeWindows:
+		m.resizeWindow("=", e.Count)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.MaximizeWindowHeight:
+		m.resizeWindow("_", e.Count)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.MaximizeWindowWidth:
+		m.resizeWindow("|", e.Count)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.Resize, event.VerticalResize:
+		if err := m.resize(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		} else {
+			m.eventCh <- event.Event{Type: event.Redraw}
+		}
+	case event.TabNew:
+		if err := m.tabNew(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		} else {
+			m.eventCh <- event.Event{Type: event.Redraw}
+		}
+	case event.TabClose:
+		if err := m.tabClose(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		} else {
+			m.eventCh <- event.Event{Type: event.Redraw}
+		}
+	case event.TabNext:
+		if err := m.tabNext(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		} else {
+			m.eventCh <- event.Event{Type: event.Redraw}
+		}
+	case event.TabPrevious:
+		m.tabPrevious(e)
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.TabMove:
+		if err := m.tabMove(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		} else {
+			m.eventCh <- event.Event{Type: event.Redraw}
+		}
 	case event.Quit:
 		if err := m.quit(e); err != nil {
 			m.eventCh <- event.Event{Type: event.Error, Error: err}
@@ -275,6 +434,50 @@ func (m *Manager) Emit(e event.Event) {
 		if err := m.writeQuit(e); err != nil {
 			m.eventCh <- event.Event{Type: event.Error, Error: err}
 		}
+	case event.FindAll:
+		if err := m.findAll(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		}
+	case event.Strings:
+		if err := m.findStrings(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		} else {
+			m.eventCh <- event.Event{Type: event.Redraw}
+		}
+	case event.QuickfixOpen, event.QuickfixClose:
+		if err := m.openQuickfix(e, e.Type == event.QuickfixOpen); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		} else {
+			m.eventCh <- event.Event{Type: event.Redraw}
+		}
+	case event.QuickfixNext, event.QuickfixPrevious, event.QuickfixGoto:
+		if msg, err := m.moveQuickfix(e); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		} else {
+			m.eventCh <- event.Event{Type: event.Info, Error: errors.New(msg)}
+		}
+	case event.SearchAll:
+		if err := m.searchAll(e, true); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		}
+	case event.ExecuteSearch:
+		m.mu.Lock()
+		m.allSearch = false
+		m.mu.Unlock()
+		m.windows[m.windowIndex].emit(e)
+	case event.NextSearch, event.PreviousSearch:
+		m.mu.Lock()
+		allSearch := m.allSearch
+		m.mu.Unlock()
+		if !allSearch {
+			m.windows[m.windowIndex].emit(e)
+		} else if err := m.searchAll(e, (e.Type == event.NextSearch) == (e.Rune == '/')); err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+		}
+	case event.AbortSearch:
+		if !m.abortFindAll() && !m.abortSearchAll() {
+			m.windows[m.windowIndex].emit(e)
+		}
 	default:
 		m.windows[m.windowIndex].emit(e)
 	}
@@ -322,9 +525,9 @@ func (m *Manager) newWindow(e event.Event, vertical bool) error {
 	}
 	m.addWindow(window)
 	if vertical {
-		m.layout = m.layout.SplitLeft(m.windowIndex).Resize(0, 0, m.width, m.height)
+		m.layout = m.resizeLayout(m.layout.SplitLeft(m.windowIndex))
 	} else {
-		m.layout = m.layout.SplitTop(m.windowIndex).Resize(0, 0, m.width, m.height)
+		m.layout = m.resizeLayout(m.layout.SplitTop(m.windowIndex))
 	}
 	return nil
 }
@@ -334,7 +537,7 @@ func (m *Manager) alternative(e event.Event) {
 	defer m.mu.Unlock()
 	if e.Count == 0 {
 		m.windowIndex, m.prevWindowIndex = m.prevWindowIndex, m.windowIndex
-	} else if 0 < e.Count && e.Count <= int64(len(m.windows)) {
+	} else if 0 < e.Count && e.Count <= int64(len(m.windows)) && m.windows[e.Count-1] != nil {
 		m.windowIndex, m.prevWindowIndex = int(e.Count)-1, m.windowIndex
 	}
 	m.layout = m.layout.Replace(m.windowIndex)
@@ -363,14 +566,16 @@ func (m *Manager) wincmd(arg string) error {
 				x.LeftMargin() < y.LeftMargin()+y.Width()
 		})
 	case "j":
-		m.focus(func(x, y layout.Window) bool {
+		if !m.focus(func(x, y layout.Window) bool {
 			return x.TopMargin()+x.Height() == y.TopMargin() &&
 				y.LeftMargin() <= x.LeftMargin() &&
 				x.LeftMargin() < y.LeftMargin()+y.Width()
-		})
+		}) {
+			m.focusQuickfix()
+		}
 	case "t":
 		m.focus(func(_, y layout.Window) bool {
-			return y.LeftMargin() == 0 && y.TopMargin() == 0
+			return y.LeftMargin() == m.layout.LeftMargin() && y.TopMargin() == m.layout.TopMargin()
 		})
 	case "b":
 		m.focus(func(_, y layout.Window) bool {
@@ -397,31 +602,130 @@ func (m *Manager) wincmd(arg string) error {
 		m.move(func(x layout.Window, y layout.Layout) layout.Layout {
 			return layout.Vertical{Left: y, Right: x}
 		})
+	case "r", "R", "x", "o":
+		m.transform(arg, 0)
+	case "+", "-", ">", "<", "=", "_", "|":
+		m.resizeWindow(arg, 0)
 	default:
 		return fmt.Errorf("Invalid argument for wincmd: %s", arg)
 	}
 	return nil
 }
 
-func (m *Manager) focus(search func(layout.Window, layout.Window) bool) {
+// focus moves to the window found by the function, and reports whether the
+// window is found.
+func (m *Manager) focus(search func(layout.Window, layout.Window) bool) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	activeWindow := m.layout.ActiveWindow()
 	newWindow := m.layout.Lookup(func(l layout.Window) bool {
 		return search(activeWindow, l)
 	})
-	if newWindow.Index >= 0 {
-		m.windowIndex, m.prevWindowIndex = newWindow.Index, m.windowIndex
-		m.layout = m.layout.Activate(m.windowIndex)
+	if newWindow.Index < 0 {
+		return false
 	}
+	m.windowIndex, m.prevWindowIndex = newWindow.Index, m.windowIndex
+	m.layout = m.layout.Activate(m.windowIndex)
+	return true
 }
 
 func (m *Manager) move(modifier func(layout.Window, layout.Layout) layout.Layout) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	activeWindow := m.layout.ActiveWindow()
-	m.layout = modifier(activeWindow, m.layout.Close()).Activate(
-		activeWindow.Index).Resize(0, 0, m.width, m.height)
+	m.layout = m.resizeLayout(modifier(activeWindow, m.layout.Close()).Activate(activeWindow.Index))
+}
+
+// transform rotates or exchanges the windows, or closes other windows.
+func (m *Manager) transform(arg string, count int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch arg {
+	case "r", "R":
+		for i := int64(0); i < mathutil.MaxInt64(count, 1); i++ {
+			m.layout = m.layout.Rotate(arg == "r")
+		}
+	case "x":
+		m.layout = m.layout.Exchange()
+	case "o":
+		m.layout = m.layout.Only()
+	}
+	if index := m.layout.ActiveWindow().Index; index != m.windowIndex {
+		m.windowIndex, m.prevWindowIndex = index, m.windowIndex
+	}
+	m.layout = m.resizeLayout(m.layout)
+}
+
+// resizeWindow resizes the active window. The count is the amount of
+// the change, or the new size for maximizing commands (_ and |).
+func (m *Manager) resizeWindow(arg string, count int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	activeWindow := m.layout.ActiveWindow()
+	n := int(mathutil.MaxInt64(count, 1))
+	switch arg {
+	case "+":
+		m.layout = m.layout.SetHeight(activeWindow.Height() + n)
+	case "-":
+		m.layout = m.layout.SetHeight(activeWindow.Height() - n)
+	case ">":
+		m.layout = m.layout.SetWidth(activeWindow.Width() + n)
+	case "<":
+		m.layout = m.layout.SetWidth(activeWindow.Width() - n)
+	case "=":
+		m.layout = m.layout.Equalize()
+	case "_":
+		if count == 0 {
+			n = m.height
+		}
+		m.layout = m.layout.SetHeight(n)
+	case "|":
+		if count == 0 {
+			n = m.width
+		}
+		m.layout = m.layout.SetWidth(n)
+	}
+	m.layout = m.resizeLayout(m.layout)
+}
+
+func (m *Manager) resize(e event.Event) error {
+	arg := e.Arg
+	if e.Type == event.VerticalResize {
+		xs := strings.Fields(arg)
+		if len(xs) == 0 || !strings.HasPrefix(xs[0], "res") || !strings.HasPrefix("resize", xs[0]) {
+			return fmt.Errorf("invalid argument for %s: %s", e.CmdName, arg)
+		}
+		arg = strings.TrimSpace(strings.TrimPrefix(arg, xs[0]))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	activeWindow := m.layout.ActiveWindow()
+	var size, n int
+	if e.Type == event.VerticalResize {
+		size = activeWindow.Width()
+	} else {
+		size = activeWindow.Height()
+	}
+	if arg != "" {
+		var err error
+		if n, err = strconv.Atoi(arg); err != nil {
+			return fmt.Errorf("invalid argument for %s: %s", e.CmdName, arg)
+		}
+		if arg[0] == '+' || arg[0] == '-' {
+			n += size
+		}
+	} else if e.Type == event.VerticalResize {
+		n = m.width
+	} else {
+		n = m.height
+	}
+	if e.Type == event.VerticalResize {
+		m.layout = m.layout.SetWidth(n)
+	} else {
+		m.layout = m.layout.SetHeight(n)
+	}
+	m.layout = m.resizeLayout(m.layout)
+	return nil
 }
 
 func (m *Manager) quit(e event.Event) error {
@@ -433,11 +737,16 @@ func (m *Manager) quit(e event.Event) error {
 		return fmt.Errorf("you have unsaved changes, use q! to force quit")
 	}
 	w, h := m.layout.Count()
-	if w == 1 && h == 1 {
+	if w == 1 && h == 1 && len(m.tabs) > 1 {
+		m.mu.Lock()
+		m.closeTab()
+		m.mu.Unlock()
+		m.eventCh <- event.Event{Type: event.Redraw}
+	} else if w == 1 && h == 1 {
 		m.eventCh <- event.Event{Type: event.QuitAll}
 	} else {
 		m.mu.Lock()
-		m.layout = m.layout.Close().Resize(0, 0, m.width, m.height)
+		m.layout = m.resizeLayout(m.layout.Close())
 		m.windowIndex, m.prevWindowIndex = m.layout.ActiveWindow().Index, m.windowIndex
 		m.mu.Unlock()
 		m.eventCh <- event.Event{Type: event.Redraw}
@@ -457,6 +766,77 @@ func (m *Manager) write(e event.Event) error {
 	return nil
 }
 
+func (m *Manager) read(e event.Event) error {
+	name, offset, length, err := parseReadArg(e.Arg)
+	if err != nil {
+		return fmt.Errorf("%s for %s", err, e.CmdName)
+	}
+	if name, err = pathutil.HomedirExpand(name); err != nil {
+		return err
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if info.IsDir() {
+		f.Close()
+		return fmt.Errorf("%s is a directory", name)
+	}
+	if offset > info.Size() {
+		f.Close()
+		return fmt.Errorf("offset out of range: 0x%x", offset)
+	}
+	if length < 0 || offset+length > info.Size() {
+		length = info.Size() - offset
+	}
+	// the file is referenced lazily by the buffer so keep it open until Close
+	r := io.NewSectionReader(f, offset, length)
+	m.mu.Lock()
+	window := m.windows[m.windowIndex]
+	m.mu.Unlock()
+	e.Buffer = buffer.NewBuffer(r)
+	n, err := window.read(e)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	m.mu.Lock()
+	m.files = append(m.files, file{name: name, file: f, reader: r, perm: info.Mode().Perm(), window: window})
+	m.mu.Unlock()
+	m.eventCh <- event.Event{Type: event.Info, Error: fmt.Errorf("%s: %d (0x%x) bytes read", name, n, n)}
+	return nil
+}
+
+// parseReadArg parses the argument of the read command;
+// {file} [offset:length] where both offset and length are optional.
+func parseReadArg(arg string) (string, int64, int64, error) {
+	if arg == "" {
+		return "", 0, 0, errors.New("an argument is required")
+	}
+	name, offset, length := arg, int64(0), int64(-1)
+	if i := strings.LastIndexAny(arg, " \t"); i > 0 && strings.ContainsRune(arg[i+1:], ':') {
+		name = strings.TrimSpace(arg[:i])
+		xs := strings.SplitN(arg[i+1:], ":", 2)
+		var err error
+		if xs[0] != "" {
+			if offset, err = strconv.ParseInt(xs[0], 0, 64); err != nil || offset < 0 {
+				return "", 0, 0, fmt.Errorf("invalid offset %s", xs[0])
+			}
+		}
+		if xs[1] != "" {
+			if length, err = strconv.ParseInt(xs[1], 0, 64); err != nil || length < 0 {
+				return "", 0, 0, fmt.Errorf("invalid length %s", xs[1])
+			}
+		}
+	}
+	return name, offset, length, nil
+}
+
 func (m *Manager) writeQuit(e event.Event) error {
 	if len(e.Arg) > 0 {
 		return fmt.Errorf("too many arguments for %s", e.CmdName)
@@ -478,9 +858,12 @@ func (m *Manager) State() (map[int]*state.WindowState, layout.Layout, int, error
 	states := make(map[int]*state.WindowState, len(m.windows))
 	for i, window := range m.windows {
 		if l, ok := layouts[i]; ok {
+			window.mu.Lock()
+			d := window.display
+			window.mu.Unlock()
 			var err error
 			if states[i], err = window.state(
-				hexWindowWidth(l.Width()), mathutil.MaxInt(l.Height()-2, 1),
+				hexWindowWidth(l.Width(), d), mathutil.MaxInt(l.Height()-2, 1),
 			); err != nil {
 				return nil, m.layout, 0, err
 			}
@@ -489,19 +872,30 @@ func (m *Manager) State() (map[int]*state.WindowState, layout.Layout, int, error
 	return states, m.layout, m.windowIndex, nil
 }
 
-func hexWindowWidth(width int) int {
-	if width > 146 {
-		return 32
-	} else if width > 114 {
-		return 24
-	} else if width > 82 {
-		return 16
-	} else if width > 64 {
-		return 12
-	} else if width > 50 {
-		return 8
+// hexWindowWidth returns the number of the bytes in a line, which fits in the
+// window with the offset, the hex and text columns, and is a multiple of the
+// word size of the display. The hex display keeps its own thresholds.
+func hexWindowWidth(width int, d display.Display) int {
+	if d.Name == "hex" {
+		if width > 146 {
+			return 32
+		} else if width > 114 {
+			return 24
+		} else if width > 82 {
+			return 16
+		} else if width > 64 {
+			return 12
+		} else if width > 50 {
+			return 8
+		}
+		return 4
+	}
+	for _, n := range []int{32, 24, 16, 12, 8} {
+		if n%d.Size == 0 && width > d.Width(n)+n+18 {
+			return n
+		}
 	}
-	return 4
+	return mathutil.MaxInt(d.Size, 4)
 }
 
 func (m *Manager) writeFile(r *event.Range, name string) (string, int64, error) {
@@ -516,9 +910,17 @@ func (m *Manager) writeFile(r *event.Range, name string) (string, int64, error)
 		return name, 0, errors.New("cannot overwrite the original file on Windows")
 	}
 	var err error
-	if name, err = homedirExpand(name); err != nil {
+	if name, err = pathutil.HomedirExpand(name); err != nil {
 		return name, 0, err
 	}
+	m.mu.Lock()
+	checksumFix := m.options.ChecksumFix
+	m.mu.Unlock()
+	if checksumFix {
+		if err := window.fixChecksums(); err != nil {
+			return name, 0, err
+		}
+	}
 	if window.filename == "" && window.name == "" {
 		window.mu.Lock()
 		window.filename = name
@@ -568,14 +970,3 @@ func (m *Manager) Close() {
 		f.file.Close()
 	}
 }
-
-func homedirExpand(path string) (string, error) {
-	if !strings.HasPrefix(path, "~") {
-		return path, nil
-	}
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(homeDir, path[1:]), nil
-}
@@ -1,6 +1,7 @@
 package window
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -10,9 +11,12 @@ import (
 	"testing"
 
 	"github.com/itchyny/bed/buffer"
+	"github.com/itchyny/bed/display"
 	"github.com/itchyny/bed/event"
 	"github.com/itchyny/bed/layout"
 	"github.com/itchyny/bed/mode"
+	"github.com/itchyny/bed/option"
+	"github.com/itchyny/bed/state"
 )
 
 func TestManagerOpenEmpty(t *testing.T) {
@@ -327,6 +331,54 @@ func TestManagerWincmd(t *testing.T) {
 	wm.Close()
 }
 
+func TestManagerRotateOnly(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event), make(chan struct{})
+	wm.Init(eventCh, redrawCh)
+	go func() {
+		for {
+			select {
+			case <-eventCh:
+			case <-redrawCh:
+			}
+		}
+	}()
+	wm.SetSize(110, 20)
+	if err := wm.Open(""); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	wm.Emit(event.Event{Type: event.New})
+	wm.Emit(event.Event{Type: event.New})
+	_, got, windowIndex, _ := wm.State()
+	expected := layout.NewLayout(0).SplitTop(1).SplitTop(2).Resize(0, 0, 110, 20)
+	if !reflect.DeepEqual(got, expected) || windowIndex != 2 {
+		t.Errorf("layout should be %#v but got %#v", expected, got)
+	}
+
+	wm.Emit(event.Event{Type: event.RotateWindowsDownwards})
+	_, got, windowIndex, _ = wm.State()
+	expected = layout.NewLayout(1).SplitTop(2).SplitTop(0).Activate(2).Resize(0, 0, 110, 20)
+	if !reflect.DeepEqual(got, expected) || windowIndex != 2 {
+		t.Errorf("layout should be %#v but got %#v", expected, got)
+	}
+
+	wm.Emit(event.Event{Type: event.Wincmd, Arg: "x"})
+	_, got, windowIndex, _ = wm.State()
+	expected = layout.NewLayout(2).SplitTop(1).SplitTop(0).Activate(1).Resize(0, 0, 110, 20)
+	if !reflect.DeepEqual(got, expected) || windowIndex != 1 {
+		t.Errorf("layout should be %#v but got %#v", expected, got)
+	}
+
+	wm.Emit(event.Event{Type: event.OnlyWindow})
+	_, got, windowIndex, _ = wm.State()
+	expected = layout.NewLayout(1).Resize(0, 0, 110, 20)
+	if !reflect.DeepEqual(got, expected) || windowIndex != 1 {
+		t.Errorf("layout should be %#v but got %#v", expected, got)
+	}
+
+	wm.Close()
+}
+
 func TestManagerCopyCutPaste(t *testing.T) {
 	wm := NewManager()
 	eventCh, redrawCh, waitCh := make(chan event.Event), make(chan struct{}), make(chan struct{})
@@ -435,3 +487,751 @@ func TestManagerCopyCutPaste(t *testing.T) {
 	<-waitCh
 	wm.Close()
 }
+
+func TestManagerRead(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	wm.Init(eventCh, redrawCh)
+	wm.SetSize(110, 20)
+	f, err := ioutil.TempFile("", "bed-test-manager-read")
+	if err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if _, err = f.WriteString("0123456789"); err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if err := wm.Open(""); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	for _, testCase := range []struct {
+		event    event.Event
+		expected string
+		message  string
+	}{
+		{event.Event{Arg: f.Name()}, "0123456789", f.Name() + ": 10 (0xa) bytes read"},
+		{event.Event{Range: &event.Range{From: event.Absolute{Offset: 2}}, Arg: f.Name() + " 7:"},
+			"0178923456789", f.Name() + ": 3 (0x3) bytes read"},
+		{event.Event{Range: &event.Range{From: event.Absolute{Offset: 13}}, Arg: f.Name() + " 0x1:0x2"},
+			"0178923456789" + "12", f.Name() + ": 2 (0x2) bytes read"},
+		{event.Event{Range: &event.Range{From: event.Absolute{Offset: 1}}, Arg: f.Name() + " :4", Bang: true},
+			"0012323456789" + "12", f.Name() + ": 4 (0x4) bytes read"},
+		{event.Event{Range: &event.Range{From: event.End{}}, Arg: f.Name() + " 8:100", Bang: true},
+			"00123234567891" + "89", f.Name() + ": 2 (0x2) bytes read"},
+		{event.Event{Arg: f.Name() + " 11:"}, "", "offset out of range: 0xb"},
+		{event.Event{Arg: f.Name() + " x:"}, "", "invalid offset x for read"},
+		{event.Event{Arg: ""}, "", "an argument is required for read"},
+		{event.Event{Range: &event.Range{From: event.Absolute{}, To: event.End{}}, Arg: f.Name()},
+			"", "range not allowed for read"},
+	} {
+		testCase.event.Type, testCase.event.CmdName, testCase.event.Mode = event.Read, "read", mode.Normal
+		wm.Emit(testCase.event)
+		if ev := <-eventCh; ev.Error.Error() != testCase.message {
+			t.Errorf("read should emit message %q but got %q", testCase.message, ev.Error.Error())
+		}
+		if testCase.expected == "" {
+			continue
+		}
+		windowStates, _, _, _ := wm.State()
+		if got := string(windowStates[0].Bytes[:windowStates[0].Size]); got != testCase.expected {
+			t.Errorf("Bytes should be %q but got %q", testCase.expected, got)
+		}
+	}
+	if len(wm.files) != 5 {
+		t.Errorf("files should be registered only on success but got %d files", len(wm.files))
+	}
+	wm.Emit(event.Event{Type: event.Undo, Mode: mode.Normal})
+	windowStates, _, _, _ := wm.State()
+	if expected, got := "0012323456789"+"12", string(windowStates[0].Bytes[:windowStates[0].Size]); got != expected {
+		t.Errorf("read should be undone: Bytes should be %q but got %q", expected, got)
+	}
+	wm.Close()
+}
+
+func TestManagerBuffers(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	wm.Init(eventCh, redrawCh)
+	wm.SetSize(110, 20)
+	var names []string
+	for _, str := range []string{"abc", "0123456789"} {
+		f, err := ioutil.TempFile("", "bed-test-manager-buffers")
+		if err != nil {
+			t.Errorf("err should be nil but got %v", err)
+		}
+		if _, err = f.WriteString(str); err != nil {
+			t.Errorf("err should be nil but got %v", err)
+		}
+		if err := f.Close(); err != nil {
+			t.Errorf("err should be nil but got: %v", err)
+		}
+		defer os.Remove(f.Name())
+		names = append(names, f.Name())
+	}
+	if err := wm.Open(names[0]); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	for _, testCase := range []struct {
+		event       event.Event
+		message     string
+		windowIndex int
+	}{
+		{event.Event{Type: event.Edit, Arg: names[1]}, "", 1},
+		{event.Event{Type: event.Buffers, CmdName: "ls"},
+			`  1 #h  "` + names[0] + `" 3 (0x3) bytes` + "\n" +
+				`  2 %a  "` + names[1] + `" 10 (0xa) bytes`, 1},
+		{event.Event{Type: event.Buffer, CmdName: "buffer", Arg: "1"}, "", 0},
+		{event.Event{Type: event.Buffer, CmdName: "buffer", Arg: names[1]}, "", 1},
+		{event.Event{Type: event.Buffer, CmdName: "buffer", Arg: "3"}, "buffer 3 does not exist", 1},
+		{event.Event{Type: event.Buffer, CmdName: "buffer", Arg: "bed-test"}, "more than one match for bed-test", 1},
+		{event.Event{Type: event.BufferNext, CmdName: "bnext"}, "", 0},
+		{event.Event{Type: event.BufferPrevious, CmdName: "bprevious", Arg: "3"}, "", 1},
+		{event.Event{Type: event.BufferDelete, CmdName: "bdelete", Arg: "1"}, "buffer 1 deleted", 1},
+		{event.Event{Type: event.Buffers, CmdName: "ls"},
+			`  2 %a  "` + names[1] + `" 10 (0xa) bytes`, 1},
+		{event.Event{Type: event.Buffer, CmdName: "buffer", Arg: "1"}, "buffer 1 does not exist", 1},
+		{event.Event{Type: event.BufferNext, CmdName: "bnext"}, "", 1},
+		{event.Event{Type: event.BufferDelete, CmdName: "bdelete"}, "buffer 2 deleted", 2},
+		{event.Event{Type: event.Buffers, CmdName: "ls"}, `  3 %a  "[No name]" 0 (0x0) bytes`, 2},
+	} {
+		wm.Emit(testCase.event)
+		if ev := <-eventCh; testCase.message == "" && ev.Type != event.Redraw {
+			t.Errorf("%s should emit redraw event but got %v", testCase.event.CmdName, ev)
+		} else if testCase.message != "" && ev.Error.Error() != testCase.message {
+			t.Errorf("%s should emit message %q but got %q", testCase.event.CmdName, testCase.message, ev.Error)
+		}
+		if _, _, windowIndex, _ := wm.State(); windowIndex != testCase.windowIndex {
+			t.Errorf("window index should be %d but got %d", testCase.windowIndex, windowIndex)
+		}
+	}
+	if len(wm.files) != 0 {
+		t.Errorf("files should be closed but got %d files", len(wm.files))
+	}
+	wm.Close()
+}
+
+func TestManagerRegister(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	wm.Init(eventCh, redrawCh)
+	wm.SetSize(110, 20)
+	f, err := ioutil.TempFile("", "bed-test-manager-register")
+	if err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if _, err = f.WriteString("0123456789"); err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if err := wm.Open(f.Name()); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	wm.Emit(event.Event{Type: event.StartVisual})
+	<-redrawCh
+	wm.Emit(event.Event{Type: event.CursorNext, Mode: mode.Visual, Count: 3})
+	<-redrawCh
+	wm.Emit(event.Event{Type: event.Copy})
+	ev := <-eventCh
+	wm.SetRegister(ev.Buffer)
+	wm.Emit(event.Event{Type: event.BufferDelete, CmdName: "bdelete"})
+	<-eventCh
+	if len(wm.files) != 1 {
+		t.Errorf("file referred by the register should be kept open but got %d files", len(wm.files))
+	}
+	if bs, err := ioutil.ReadAll(ev.Buffer); err != nil || string(bs) != "0123" {
+		t.Errorf("register should be readable but got %q, %v", string(bs), err)
+	}
+	wm.SetRegister(nil)
+	if len(wm.files) != 0 {
+		t.Errorf("files should be closed but got %d files", len(wm.files))
+	}
+	wm.Close()
+}
+
+func TestManagerSession(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	wm.Init(eventCh, redrawCh)
+	wm.SetSize(110, 20)
+	f, err := ioutil.TempFile("", "bed-test-manager-session")
+	if err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if _, err = f.WriteString(strings.Repeat("0123456789", 100)); err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	defer os.Remove(f.Name())
+	sessionName := f.Name() + ".session"
+	defer os.Remove(sessionName)
+	if err := wm.Open(f.Name()); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	wm.Emit(event.Event{Type: event.CursorNext, Count: 300, Mode: mode.Normal})
+	wm.Emit(event.Event{Type: event.SetMark, Rune: 'a', Mode: mode.Normal})
+	wm.Emit(event.Event{Type: event.Vnew})
+	wm.Emit(event.Event{Type: event.IncreaseWindowWidth, Count: 10})
+	wm.Emit(event.Event{Type: event.TabNew, Arg: "#1"})
+	wm.Emit(event.Event{Type: event.CursorNext, Count: 20, Mode: mode.Normal})
+	wm.Emit(event.Event{Type: event.TabPrevious})
+	for len(eventCh) > 0 {
+		<-eventCh
+	}
+	options := option.Default()
+	options.Encoding, options.OffsetOrigin, options.WrapScan = "latin1", 0x8000, false
+	wm.SetOptions(options)
+	wm.Emit(event.Event{Type: event.MakeSession, CmdName: "mksession", Arg: sessionName})
+	if ev := <-eventCh; ev.Error.Error() != "session saved to "+sessionName {
+		t.Errorf("mksession should emit saved message but got %q", ev.Error)
+	}
+	wm.Emit(event.Event{Type: event.MakeSession, CmdName: "mksession", Arg: sessionName})
+	if ev := <-eventCh; ev.Error.Error() != sessionName+" exists, use mksession! to overwrite" {
+		t.Errorf("mksession should emit error but got %q", ev.Error)
+	}
+	windowStates, l, windowIndex, _ := wm.State()
+	tabs, tabIndex := wm.Tabs()
+	if len(tabs) != 2 || len(windowStates) != 2 {
+		t.Errorf("tabs and windows should be saved but got %d tabs and %d windows", len(tabs), len(windowStates))
+	}
+	wm.Close()
+
+	wm = NewManager()
+	wm.Init(eventCh, redrawCh)
+	wm.SetSize(110, 20)
+	if err := wm.OpenSession(sessionName); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	gotWindowStates, gotLayout, gotWindowIndex, _ := wm.State()
+	if !reflect.DeepEqual(gotLayout, l) || gotWindowIndex != windowIndex {
+		t.Errorf("layout should be %#v but got %#v", l, gotLayout)
+	}
+	for i, ws := range windowStates {
+		if got := gotWindowStates[i]; got.Name != ws.Name || got.Cursor != ws.Cursor || got.Offset != ws.Offset {
+			t.Errorf("window state should be %#v but got %#v", ws, got)
+		}
+	}
+	if gotTabs, gotTabIndex := wm.Tabs(); !reflect.DeepEqual(gotTabs, tabs) || gotTabIndex != tabIndex {
+		t.Errorf("tabs should be %#v but got %#v", tabs, gotTabs)
+	}
+	if offset := wm.windows[0].marks['a']; offset != 300 {
+		t.Errorf("mark should be restored to %d but got %d", 300, offset)
+	}
+	if got := wm.Options(); got != options {
+		t.Errorf("options should be restored to %+v but got %+v", options, got)
+	}
+	if w := wm.windows[0]; w.encoding != "latin1" || w.offsetOrigin != 0x8000 || w.wrapScan {
+		t.Errorf("options should be restored to the windows but got %q, %d, %v", w.encoding, w.offsetOrigin, w.wrapScan)
+	}
+	if err := wm.OpenSession(f.Name()); err == nil || !strings.HasPrefix(err.Error(), "invalid session file") {
+		t.Errorf("err should be invalid session file but got: %v", err)
+	}
+	buffers := fmt.Sprintf(`"buffers":[{"filename":%q},{"filename":%q}]`, f.Name(), f.Name())
+	files := len(wm.files)
+	for _, layoutJSON := range []string{
+		`{"window":0},"windowIndex":0`,
+		`{"window":0,"active":true},"windowIndex":1`,
+		`{"vertical":[{"window":0,"active":true},{"window":1,"active":true}]},"windowIndex":0`,
+	} {
+		str := `{` + buffers + `,"tabs":[{"layout":` + layoutJSON + `,"prevWindowIndex":0}],"tabIndex":0}`
+		if err := ioutil.WriteFile(sessionName, []byte(str), 0644); err != nil {
+			t.Errorf("err should be nil but got: %v", err)
+		}
+		if err := wm.OpenSession(sessionName); err == nil || !strings.HasPrefix(err.Error(), "invalid session file") {
+			t.Errorf("err should be invalid session file for %s but got: %v", layoutJSON, err)
+		}
+	}
+	if len(wm.files) != files {
+		t.Errorf("invalid session file should not open files but got %d files", len(wm.files))
+	}
+	wm.Close()
+}
+
+func TestManagerResizeWindow(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event), make(chan struct{})
+	wm.Init(eventCh, redrawCh)
+	go func() {
+		for {
+			select {
+			case <-eventCh:
+			case <-redrawCh:
+			}
+		}
+	}()
+	wm.SetSize(110, 20)
+	if err := wm.Open(""); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	wm.Emit(event.Event{Type: event.New})
+	wm.Emit(event.Event{Type: event.Vnew})
+	for _, testCase := range []struct {
+		event  event.Event
+		width  int
+		height int
+	}{
+		{event.Event{}, 55, 10},
+		{event.Event{Type: event.IncreaseWindowHeight}, 55, 11},
+		{event.Event{Type: event.DecreaseWindowHeight, Count: 3}, 55, 8},
+		{event.Event{Type: event.IncreaseWindowWidth, Count: 10}, 65, 8},
+		{event.Event{Type: event.DecreaseWindowWidth}, 64, 8},
+		{event.Event{Type: event.MaximizeWindowHeight}, 64, 19},
+		{event.Event{Type: event.MaximizeWindowWidth, Count: 30}, 30, 19},
+		{event.Event{Type: event.EqualizeWindows}, 55, 10},
+		{event.Event{Type: event.Resize, Arg: "5"}, 55, 5},
+		{event.Event{Type: event.Resize, Arg: "+3"}, 55, 8},
+		{event.Event{Type: event.VerticalResize, Arg: "resize 20"}, 20, 8},
+		{event.Event{Type: event.VerticalResize, Arg: "res -5"}, 15, 8},
+		{event.Event{Type: event.Wincmd, Arg: "="}, 55, 10},
+	} {
+		if testCase.event.Type != event.Nop {
+			wm.Emit(testCase.event)
+		}
+		_, l, _, _ := wm.State()
+		if w := l.ActiveWindow(); w.Width() != testCase.width || w.Height() != testCase.height {
+			t.Errorf("window size should be %dx%d but got %dx%d",
+				testCase.width, testCase.height, w.Width(), w.Height())
+		}
+	}
+	wm.Close()
+}
+
+func TestManagerTabs(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event), make(chan struct{})
+	wm.Init(eventCh, redrawCh)
+	go func() {
+		for {
+			select {
+			case <-eventCh:
+			case <-redrawCh:
+			}
+		}
+	}()
+	wm.SetSize(110, 20)
+	if err := wm.Open(""); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	tabIndices := func() []int {
+		wm.mu.Lock()
+		defer wm.mu.Unlock()
+		xs := make([]int, len(wm.tabs))
+		for i, t := range wm.tabs {
+			if i == wm.tabIndex {
+				xs[i] = wm.windowIndex
+			} else {
+				xs[i] = t.windowIndex
+			}
+		}
+		return xs
+	}
+	for _, testCase := range []struct {
+		event    event.Event
+		indices  []int
+		tabIndex int
+		top      int
+	}{
+		{event.Event{Type: event.TabNew}, []int{0, 1}, 1, 1},
+		{event.Event{Type: event.TabNew, Arg: "#1"}, []int{0, 1, 0}, 2, 1},
+		{event.Event{Type: event.New}, []int{0, 1, 2}, 2, 1},
+		{event.Event{Type: event.TabNext}, []int{0, 1, 2}, 0, 1},
+		{event.Event{Type: event.TabNext, Count: 2}, []int{0, 1, 2}, 1, 1},
+		{event.Event{Type: event.TabPrevious, Count: 2}, []int{0, 1, 2}, 2, 1},
+		{event.Event{Type: event.TabMove, Arg: "0"}, []int{2, 0, 1}, 0, 1},
+		{event.Event{Type: event.TabMove, Arg: "+1"}, []int{0, 2, 1}, 1, 1},
+		{event.Event{Type: event.TabMove}, []int{0, 1, 2}, 2, 1},
+		{event.Event{Type: event.TabMove, Arg: "1"}, []int{0, 2, 1}, 1, 1},
+		{event.Event{Type: event.TabClose}, []int{0, 1}, 1, 1},
+		{event.Event{Type: event.Quit}, []int{0}, 0, 0},
+		{event.Event{Type: event.TabClose}, []int{0}, 0, 0},
+	} {
+		wm.Emit(testCase.event)
+		if got := tabIndices(); !reflect.DeepEqual(got, testCase.indices) {
+			t.Errorf("window indices of tabs should be %v but got %v", testCase.indices, got)
+		}
+		tabs, tabIndex := wm.Tabs()
+		if len(tabs) != len(testCase.indices) || tabIndex != testCase.tabIndex {
+			t.Errorf("tab index should be %d but got %d", testCase.tabIndex, tabIndex)
+		}
+		if _, l, _, _ := wm.State(); l.TopMargin() != testCase.top {
+			t.Errorf("top margin of layout should be %d but got %d", testCase.top, l.TopMargin())
+		}
+	}
+	wm.Close()
+}
+
+func TestManagerQuickfix(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	wm.Init(eventCh, redrawCh)
+	wm.SetSize(110, 20)
+	f, err := ioutil.TempFile("", "bed-test-manager-quickfix")
+	if err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if _, err = f.WriteString("abc\x00abcdef"); err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if err := wm.Open(f.Name()); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	for _, testCase := range []struct {
+		event   event.Event
+		message string
+		cursor  int64
+		height  int
+	}{
+		{event.Event{Type: event.FindAll, CmdName: "findall", Arg: "bc"}, "2 matches for: bc", 0, 0},
+		{event.Event{Type: event.QuickfixNext, CmdName: "cnext"}, "(1 of 2): :findall bc", 1, 0},
+		{event.Event{Type: event.QuickfixNext, CmdName: "cnext"}, "(2 of 2): :findall bc", 5, 0},
+		{event.Event{Type: event.QuickfixNext, CmdName: "cnext"}, "no more items", 5, 0},
+		{event.Event{Type: event.QuickfixGoto, CmdName: "cc", Arg: "1"}, "(1 of 2): :findall bc", 1, 0},
+		{event.Event{Type: event.QuickfixGoto, CmdName: "cc", Arg: "3"}, "invalid argument for cc: 3", 1, 0},
+		{event.Event{Type: event.QuickfixOpen, CmdName: "copen"}, "", 1, 10},
+		{event.Event{Type: event.QuickfixPrevious, CmdName: "cprevious"}, "no more items", 1, 10},
+		{event.Event{Type: event.FindAll, CmdName: "findall", Arg: "xyz"}, "pattern not found: xyz", 1, 10},
+		{event.Event{Type: event.QuickfixNext, CmdName: "cnext"}, "no quickfix list", 1, 10},
+		{event.Event{Type: event.FindAll, CmdName: "findall"}, "empty pattern for findall", 1, 10},
+		{event.Event{Type: event.QuickfixClose, CmdName: "cclose"}, "", 1, 0},
+	} {
+		wm.Emit(testCase.event)
+		if ev := <-eventCh; testCase.message == "" && ev.Type != event.Redraw {
+			t.Errorf("%s should emit redraw event but got %v", testCase.event.CmdName, ev)
+		} else if testCase.message != "" && (ev.Error == nil || ev.Error.Error() != testCase.message) {
+			t.Errorf("%s should emit message %q but got %v", testCase.event.CmdName, testCase.message, ev.Error)
+		}
+		windowStates, l, _, _ := wm.State()
+		if windowStates[0].Cursor != testCase.cursor {
+			t.Errorf("cursor should be %d but got %d", testCase.cursor, windowStates[0].Cursor)
+		}
+		if l.Height() != 20-testCase.height {
+			t.Errorf("height of layout should be %d but got %d", 20-testCase.height, l.Height())
+		}
+		if s := wm.Quickfix(); testCase.height == 0 && s != nil {
+			t.Errorf("quickfix list should be closed but got %+v", s)
+		} else if testCase.height > 0 && (s == nil || s.Height != testCase.height) {
+			t.Errorf("quickfix list should be opened but got %+v", s)
+		}
+	}
+	wm.mu.Lock()
+	wm.quickfix.open = true
+	wm.mu.Unlock()
+	wm.Emit(event.Event{Type: event.FindAll, CmdName: "findall", Arg: "abc"})
+	<-eventCh
+	wm.Emit(event.Event{Type: event.QuickfixNext, CmdName: "cnext"})
+	<-eventCh
+	expected := &state.QuickfixState{
+		Title: ":findall abc",
+		Entries: []state.QuickfixEntry{
+			{Offset: 0, Text: "61 62 63 00 61 62 63 64 65 66                    abc.abcdef"},
+			{Offset: 4, Text: "61 62 63 64 65 66                                abcdef"},
+		},
+		Index:  0,
+		Total:  2,
+		Height: 10,
+	}
+	if s := wm.Quickfix(); !reflect.DeepEqual(s, expected) {
+		t.Errorf("quickfix state should be %+v but got %+v", expected, s)
+	}
+	wm.Emit(event.Event{Type: event.QuickfixOpen, CmdName: "copen"})
+	<-eventCh
+	wm.Emit(event.Event{Type: event.DeleteByte, Mode: mode.Normal})
+	if ev := <-eventCh; ev.Type != event.Redraw {
+		t.Errorf("x should emit redraw event but got %v", ev)
+	}
+	windowStates, _, _, _ := wm.State()
+	if expected, got := "abc\x00abcdef", string(windowStates[0].Bytes[:windowStates[0].Size]); got != expected {
+		t.Errorf("x should be ignored in the quickfix list: bytes should be %q but got %q", expected, got)
+	}
+	if s := wm.Quickfix(); !s.Focused {
+		t.Errorf("quickfix list should be focused but got %+v", s)
+	}
+	wm.Emit(event.Event{Type: event.BufferDelete, CmdName: "bdelete"})
+	if ev, expected := <-eventCh, "buffer 1 deleted"; ev.Error == nil || ev.Error.Error() != expected {
+		t.Errorf("bdelete should emit message %q but got %v", expected, ev.Error)
+	}
+	if s := wm.Quickfix(); s != nil {
+		t.Errorf("quickfix list of the deleted buffer should be cleared but got %+v", s)
+	}
+	if _, l, _, _ := wm.State(); l.Height() != 20 {
+		t.Errorf("height of layout should be %d but got %d", 20, l.Height())
+	}
+	wm.Emit(event.Event{Type: event.QuickfixNext, CmdName: "cnext"})
+	if ev, expected := <-eventCh, "no quickfix list"; ev.Error == nil || ev.Error.Error() != expected {
+		t.Errorf("cnext should emit message %q but got %v", expected, ev.Error)
+	}
+	wm.Close()
+}
+
+func TestManagerChecksumFix(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	wm.Init(eventCh, redrawCh)
+	wm.SetSize(110, 20)
+	f, err := ioutil.TempFile("", "bed-test-manager-checksumfix")
+	if err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if _, err = f.WriteString("123456789\x00\x00"); err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if err := wm.Open(f.Name()); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	for _, testCase := range []struct {
+		event    event.Event
+		options  option.Options
+		expected string
+	}{
+		{event.Event{Type: event.ChecksumFix, CmdName: "checksum-fix", Arg: "crc16 data=0,8 store=9"},
+			option.Default(), "123456789\x00\x00"},
+		{event.Event{Type: event.Fill, CmdName: "fill", Range: &event.Range{From: event.Absolute{}}, Arg: "0x30"},
+			option.Default(), "123456789\x00\x00"},
+		{event.Event{Type: event.Write, CmdName: "write"},
+			option.Default(), "023456789\xbb\x3d"},
+		{event.Event{Type: event.Write, CmdName: "write"},
+			option.Options{ChecksumFix: true, Encoding: "utf-8"}, "023456789\x2b\x30"},
+	} {
+		wm.SetOptions(testCase.options)
+		wm.Emit(testCase.event)
+		<-eventCh
+		bs, err := ioutil.ReadFile(f.Name())
+		if err != nil {
+			t.Errorf("err should be nil but got %v", err)
+		}
+		if string(bs) != testCase.expected {
+			t.Errorf("file should be %q but got %q", testCase.expected, string(bs))
+		}
+	}
+	wm.Close()
+}
+
+func TestManagerStrings(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	wm.Init(eventCh, redrawCh)
+	wm.SetSize(110, 20)
+	f, err := ioutil.TempFile("", "bed-test-manager-strings")
+	if err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if _, err = f.WriteString("\x00\x01hello\x00abc\x00\x00w\x00o\x00r\x00l\x00d\x00"); err != nil {
+		t.Errorf("err should be nil but got %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if err := wm.Open(f.Name()); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	for _, testCase := range []struct {
+		event   event.Event
+		message string
+		cursor  int64
+		total   int
+	}{
+		{event.Event{Type: event.Strings, CmdName: "strings"}, "2 strings found", 0, 2},
+		{event.Event{Type: event.QuickfixNext, CmdName: "cnext"}, "(1 of 2): :strings 4", 2, 2},
+		{event.Event{Type: event.QuickfixNext, CmdName: "cnext"}, "(2 of 2): :strings 4", 13, 2},
+		{event.Event{Type: event.Strings, CmdName: "strings", Arg: "3"}, "3 strings found", 13, 3},
+		{event.Event{Type: event.QuickfixGoto, CmdName: "cc", Arg: "2"}, "(2 of 3): :strings 3", 8, 3},
+		{event.Event{Type: event.Strings, CmdName: "strings", Arg: "6"}, "no strings found", 8, 0},
+		{event.Event{Type: event.Strings, CmdName: "strings", Arg: "x"}, "invalid argument for strings: x", 8, 0},
+	} {
+		wm.Emit(testCase.event)
+		var ev event.Event
+		for ev = <-eventCh; ev.Type == event.Redraw; ev = <-eventCh {
+		}
+		if ev.Error == nil || ev.Error.Error() != testCase.message {
+			t.Errorf("%s should emit message %q but got %v", testCase.event.CmdName, testCase.message, ev.Error)
+		}
+		windowStates, _, _, _ := wm.State()
+		if windowStates[0].Cursor != testCase.cursor {
+			t.Errorf("cursor should be %d but got %d", testCase.cursor, windowStates[0].Cursor)
+		}
+		if s := wm.Quickfix(); s == nil || s.Total != testCase.total {
+			t.Errorf("quickfix list should have %d entries but got %+v", testCase.total, s)
+		}
+	}
+	wm.Emit(event.Event{Type: event.Strings, CmdName: "strings"})
+	for ev := <-eventCh; ev.Type == event.Redraw; ev = <-eventCh {
+	}
+	expected := &state.QuickfixState{
+		Title: ":strings 4",
+		Entries: []state.QuickfixEntry{
+			{Offset: 2, Text: "ascii     hello"},
+			{Offset: 13, Text: "utf-16le  world"},
+		},
+		Index:   -1,
+		Total:   2,
+		Height:  10,
+		Focused: true,
+	}
+	if s := wm.Quickfix(); !reflect.DeepEqual(s, expected) {
+		t.Errorf("quickfix state should be %+v but got %+v", expected, s)
+	}
+	for _, testCase := range []struct {
+		event   event.Event
+		message string
+		index   int
+		focused bool
+		cursor  int64
+	}{
+		{event.Event{Type: event.CursorDown, Count: 3}, "", 1, true, 8},
+		{event.Event{Type: event.CursorUp}, "", 0, true, 8},
+		{event.Event{Type: event.PageEnd}, "", 1, true, 8},
+		{event.Event{Type: event.QuickfixSelect}, "(2 of 2): :strings 4", 1, false, 13},
+		{event.Event{Type: event.FocusWindowDown}, "", 1, true, 13},
+		{event.Event{Type: event.PageTop}, "", 0, true, 13},
+		{event.Event{Type: event.FocusWindowUp}, "", 0, false, 13},
+		{event.Event{Type: event.QuickfixOpen}, "", 0, true, 13},
+		{event.Event{Type: event.QuickfixSelect}, "(1 of 2): :strings 4", 0, false, 2},
+	} {
+		wm.Emit(testCase.event)
+		var ev event.Event
+		for ev = <-eventCh; testCase.message != "" && ev.Type == event.Redraw; ev = <-eventCh {
+		}
+		if testCase.message != "" && (ev.Error == nil || ev.Error.Error() != testCase.message) {
+			t.Errorf("%v should emit message %q but got %v", testCase.event.Type, testCase.message, ev.Error)
+		}
+		if s := wm.Quickfix(); s.Index != testCase.index || s.Focused != testCase.focused {
+			t.Errorf("quickfix index and focus should be %d and %v but got %+v", testCase.index, testCase.focused, s)
+		}
+		windowStates, _, _, _ := wm.State()
+		if windowStates[0].Cursor != testCase.cursor {
+			t.Errorf("cursor should be %d but got %d", testCase.cursor, windowStates[0].Cursor)
+		}
+	}
+	wm.Close()
+}
+
+func TestManagerSearchAll(t *testing.T) {
+	wm := NewManager()
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	wm.Init(eventCh, redrawCh)
+	wm.SetSize(110, 20)
+	var names []string
+	for _, str := range []string{"abc", "xyzab", "0ab"} {
+		f, err := ioutil.TempFile("", "bed-test-manager-searchall")
+		if err != nil {
+			t.Errorf("err should be nil but got %v", err)
+		}
+		if _, err = f.WriteString(str); err != nil {
+			t.Errorf("err should be nil but got %v", err)
+		}
+		if err := f.Close(); err != nil {
+			t.Errorf("err should be nil but got: %v", err)
+		}
+		defer os.Remove(f.Name())
+		names = append(names, f.Name())
+	}
+	if err := wm.Open(names[0]); err != nil {
+		t.Errorf("err should be nil but got: %v", err)
+	}
+	for _, name := range names[1:] {
+		wm.Emit(event.Event{Type: event.Edit, Arg: name})
+		<-eventCh
+	}
+	wm.Emit(event.Event{Type: event.Buffer, CmdName: "buffer", Arg: "1"})
+	<-eventCh
+	for _, testCase := range []struct {
+		event       event.Event
+		wrapScan    bool
+		message     string
+		windowIndex int
+		cursor      int64
+	}{
+		{event.Event{Type: event.SearchAll, CmdName: "searchall", Arg: "ab"}, true,
+			"search hit BOTTOM, continuing in buffer 2", 1, 3},
+		{event.Event{Type: event.NextSearch, Arg: "ab", Rune: '/'}, true,
+			"search hit BOTTOM, continuing in buffer 3", 2, 1},
+		{event.Event{Type: event.NextSearch, Arg: "ab", Rune: '/'}, true,
+			"search hit BOTTOM, continuing in buffer 1", 0, 0},
+		{event.Event{Type: event.PreviousSearch, Arg: "ab", Rune: '/'}, true,
+			"search hit TOP, continuing in buffer 3", 2, 1},
+		{event.Event{Type: event.SearchAll, CmdName: "searchall", Arg: "ab/e"}, true,
+			"search hit BOTTOM, continuing in buffer 1", 0, 1},
+		{event.Event{Type: event.SearchAll, CmdName: "searchall", Arg: "bc"}, true,
+			"search hit BOTTOM, continuing at TOP", 0, 1},
+		{event.Event{Type: event.SearchAll, CmdName: "searchall", Arg: "zz"}, true,
+			"pattern not found: zz", 0, 1},
+		{event.Event{Type: event.SearchAll, CmdName: "searchall", Arg: "abc"}, false,
+			"search hit BOTTOM without match for: abc", 0, 1},
+		{event.Event{Type: event.SearchAll, CmdName: "searchall"}, true,
+			"empty pattern for searchall", 0, 1},
+	} {
+		wm.SetOptions(option.Options{Encoding: "utf-8", WrapScan: testCase.wrapScan})
+		wm.Emit(testCase.event)
+		if ev := <-eventCh; ev.Error == nil || ev.Error.Error() != testCase.message {
+			t.Errorf("search should emit message %q but got %v", testCase.message, ev.Error)
+		}
+		windowStates, _, windowIndex, _ := wm.State()
+		if windowIndex != testCase.windowIndex {
+			t.Errorf("window index should be %d but got %d", testCase.windowIndex, windowIndex)
+		}
+		if cursor := windowStates[windowIndex].Cursor; cursor != testCase.cursor {
+			t.Errorf("cursor should be %d but got %d", testCase.cursor, cursor)
+		}
+	}
+	wm.Emit(event.Event{Type: event.ExecuteSearch, Arg: "c", Rune: '/'})
+	if wm.allSearch {
+		t.Errorf("search should not continue across buffers after searching in a window")
+	}
+	quit := make(chan struct{})
+	wm.mu.Lock()
+	wm.allSearchQuit, wm.allSearchWindows = quit, []*window{wm.windows[0], wm.windows[1]}
+	wm.mu.Unlock()
+	wm.Emit(event.Event{Type: event.BufferDelete, CmdName: "bdelete", Arg: "2", Bang: true})
+	for ev := <-eventCh; ev.Type != event.Info; ev = <-eventCh {
+	}
+	select {
+	case <-quit:
+	default:
+		t.Errorf("search across the deleted buffer should be aborted")
+	}
+	wm.Close()
+}
+
+func TestHexWindowWidth(t *testing.T) {
+	for _, testCase := range []struct {
+		width    int
+		display  string
+		expected int
+	}{
+		{147, "hex", 32},
+		{146, "hex", 24},
+		{83, "hex", 16},
+		{65, "hex", 12},
+		{64, "hex", 8},
+		{50, "hex", 4},
+		{147, "binary", 12},
+		{110, "binary", 8},
+		{90, "decimal", 12},
+		{110, "word16le", 24},
+		{110, "word64be", 24},
+		{70, "word64be", 16},
+		{30, "word64le", 8},
+	} {
+		d, _ := display.Lookup(testCase.display)
+		if got := hexWindowWidth(testCase.width, d); got != testCase.expected {
+			t.Errorf("hexWindowWidth(%d, %s) should be %d but got %d",
+				testCase.width, testCase.display, testCase.expected, got)
+		}
+	}
+}
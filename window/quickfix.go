@@ -0,0 +1,376 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/mathutil"
+	"github.com/itchyny/bed/searcher"
+	"github.com/itchyny/bed/state"
+)
+
+const (
+	quickfixHeight      = 10
+	quickfixContextSize = 16
+	maxQuickfixEntries  = 1 << 20
+)
+
+// quickfix holds the list of the offsets found by :findall or :strings in
+// a window. The list is shown at the bottom of the windows while it is opened,
+// and the entries are selected with the keys while it has the focus.
+type quickfix struct {
+	title   string
+	window  *window
+	entries []quickfixEntry
+	index   int
+	top     int
+	open    bool
+	focus   bool
+	running bool
+	quit    chan struct{}
+}
+
+// quickfixEntry is an entry of the quickfix list. The bytes at the offset
+// are shown in the list if the text is empty.
+type quickfixEntry struct {
+	offset int64
+	text   string
+}
+
+func newQuickfix() *quickfix {
+	return &quickfix{index: -1}
+}
+
+func (qf *quickfix) abort() bool {
+	if qf.quit == nil {
+		return false
+	}
+	close(qf.quit)
+	qf.quit = nil
+	return true
+}
+
+// quickfixHeight returns the height of the quickfix list including its
+// status line, or zero if the list is closed.
+func (m *Manager) quickfixHeight() int {
+	if m.quickfix == nil || !m.quickfix.open {
+		return 0
+	}
+	return mathutil.MinInt(quickfixHeight, m.height/2)
+}
+
+func (m *Manager) findAll(e event.Event) error {
+	pattern := e.Arg
+	if pattern == "" {
+		return fmt.Errorf("empty pattern for %s", e.CmdName)
+	}
+	if _, _, err := searcher.DecodeSearchPattern(pattern); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.startQuickfix(":"+e.CmdName+" "+pattern, m.quickfix.open,
+		func(r io.ReaderAt, quit <-chan struct{}, add func([]quickfixEntry) bool) error {
+			return searcher.FindAll(r, pattern, quit, func(offsets []int64) bool {
+				entries := make([]quickfixEntry, len(offsets))
+				for i, offset := range offsets {
+					entries[i].offset = offset
+				}
+				return add(entries)
+			})
+		},
+		func(count int) error {
+			if count == 0 {
+				return errors.New("pattern not found: " + pattern)
+			}
+			return fmt.Errorf("%d matches for: %s", count, pattern)
+		},
+	)
+	return nil
+}
+
+// findStrings lists the printable strings in the buffer, and opens the list.
+func (m *Manager) findStrings(e event.Event) error {
+	minLen := 4
+	if arg := strings.TrimSpace(e.Arg); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid argument for %s: %s", e.CmdName, arg)
+		}
+		minLen = n
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.startQuickfix(fmt.Sprintf(":%s %d", e.CmdName, minLen), true,
+		func(r io.ReaderAt, quit <-chan struct{}, add func([]quickfixEntry) bool) error {
+			return searcher.FindStrings(r, minLen, quit, func(xs []searcher.String) bool {
+				entries := make([]quickfixEntry, len(xs))
+				for i, x := range xs {
+					entries[i] = quickfixEntry{x.Offset, fmt.Sprintf("%-8s  %s", x.Encoding, x.Text)}
+				}
+				return add(entries)
+			})
+		},
+		func(count int) error {
+			if count == 0 {
+				return errors.New("no strings found")
+			}
+			return fmt.Errorf("%d strings found", count)
+		},
+	)
+	return nil
+}
+
+// startQuickfix replaces the quickfix list with a new list of the current
+// window, and collects the entries in the background. The list is redrawn
+// on the way while it is opened. The message is an error if the list is
+// empty.
+func (m *Manager) startQuickfix(
+	title string, open bool,
+	collect func(io.ReaderAt, <-chan struct{}, func([]quickfixEntry) bool) error,
+	message func(int) error,
+) {
+	m.quickfix.abort()
+	window := m.windows[m.windowIndex]
+	window.mu.Lock()
+	r := window.buffer.Clone()
+	window.mu.Unlock()
+	qf := &quickfix{
+		title:   title,
+		window:  window,
+		index:   -1,
+		open:    open,
+		focus:   open && (m.quickfix.focus || !m.quickfix.open),
+		running: true,
+		quit:    make(chan struct{}),
+	}
+	if open != m.quickfix.open {
+		defer func() { m.layout = m.resizeLayout(m.layout) }()
+	}
+	m.quickfix = qf
+	go func() {
+		var truncated bool
+		err := collect(r, qf.quit, func(entries []quickfixEntry) bool {
+			m.mu.Lock()
+			if n := maxQuickfixEntries - len(qf.entries); len(entries) >= n {
+				entries, truncated = entries[:n], true
+			}
+			qf.entries = append(qf.entries, entries...)
+			open := qf.open && qf == m.quickfix
+			m.mu.Unlock()
+			if open {
+				m.redrawCh <- struct{}{}
+			}
+			return !truncated
+		})
+		m.mu.Lock()
+		qf.running, qf.quit = false, nil
+		count := len(qf.entries)
+		m.mu.Unlock()
+		msg := message(count)
+		switch {
+		case err != nil:
+			m.eventCh <- event.Event{Type: event.Info, Error: fmt.Errorf("%s; %s", err, msg)}
+		case count == 0:
+			m.eventCh <- event.Event{Type: event.Error, Error: msg}
+		case truncated:
+			m.eventCh <- event.Event{Type: event.Info, Error: fmt.Errorf("%s (the list is truncated)", msg)}
+		default:
+			m.eventCh <- event.Event{Type: event.Info, Error: msg}
+		}
+	}()
+}
+
+func (m *Manager) abortFindAll() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.quickfix.abort()
+}
+
+func (m *Manager) openQuickfix(e event.Event, open bool) error {
+	if len(e.Arg) > 0 {
+		return fmt.Errorf("too many arguments for %s", e.CmdName)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.quickfix.open, m.quickfix.focus = open, open
+	m.layout = m.resizeLayout(m.layout)
+	return nil
+}
+
+// focusQuickfix moves the focus to the quickfix list if it is opened.
+func (m *Manager) focusQuickfix() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.quickfix.focus = m.quickfix.open
+}
+
+// emitQuickfix handles the event while the quickfix list has the focus, and
+// reports whether the event is handled. The cursor keys select the entry,
+// and the enter key moves to the entry and the focus back to the window.
+// The other keys are ignored, but the commands are handled by the window.
+func (m *Manager) emitQuickfix(e event.Event) bool {
+	m.mu.Lock()
+	qf := m.quickfix
+	if !qf.open || !qf.focus {
+		m.mu.Unlock()
+		return false
+	}
+	switch e.Type {
+	case event.CursorUp, event.CursorDown, event.PageTop, event.PageEnd:
+		count := int(mathutil.MaxInt64(e.Count, 1))
+		switch index := mathutil.MaxInt(qf.index, 0); e.Type {
+		case event.CursorUp:
+			qf.index = index - count
+		case event.CursorDown:
+			qf.index = index + count
+		case event.PageTop:
+			qf.index = 0
+		default:
+			qf.index = len(qf.entries) - 1
+		}
+		qf.index = mathutil.MaxInt(mathutil.MinInt(qf.index, len(qf.entries)-1), 0)
+		if len(qf.entries) == 0 {
+			qf.index = -1
+		}
+		m.mu.Unlock()
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.QuickfixSelect:
+		m.mu.Unlock()
+		msg, err := m.moveQuickfix(event.Event{Type: event.QuickfixGoto})
+		if err != nil {
+			m.eventCh <- event.Event{Type: event.Error, Error: err}
+			return true
+		}
+		m.mu.Lock()
+		qf.focus = false
+		m.mu.Unlock()
+		m.eventCh <- event.Event{Type: event.Info, Error: errors.New(msg)}
+	case event.FocusWindowUp, event.FocusWindowPrevious:
+		qf.focus = false
+		m.mu.Unlock()
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.FocusWindowDown:
+		m.mu.Unlock()
+		m.eventCh <- event.Event{Type: event.Redraw}
+	case event.FocusWindowLeft, event.FocusWindowRight,
+		event.FocusWindowTopLeft, event.FocusWindowBottomRight,
+		event.ExecuteSearch, event.IncrementalSearch, event.NextSearch, event.PreviousSearch:
+		qf.focus = false
+		m.mu.Unlock()
+		return false
+	case event.ExitCmdline, event.AbortSearch, event.Quit, event.WriteQuit:
+		m.mu.Unlock()
+		return false
+	default:
+		m.mu.Unlock()
+		if e.CmdName != "" {
+			return false
+		}
+		// the window looks inactive, so the other keys are ignored
+		m.eventCh <- event.Event{Type: event.Redraw}
+	}
+	return true
+}
+
+// moveQuickfix moves to the entry of the quickfix list, and returns the
+// message of the entry.
+func (m *Manager) moveQuickfix(e event.Event) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	qf := m.quickfix
+	if len(qf.entries) == 0 {
+		return "", errors.New("no quickfix list")
+	}
+	var index int
+	switch e.Type {
+	case event.QuickfixNext, event.QuickfixPrevious:
+		if len(e.Arg) > 0 {
+			return "", fmt.Errorf("too many arguments for %s", e.CmdName)
+		}
+		if index = qf.index + 1; e.Type == event.QuickfixPrevious {
+			index = qf.index - 1
+		}
+		if index < 0 || len(qf.entries) <= index {
+			return "", errors.New("no more items")
+		}
+	default:
+		index = mathutil.MaxInt(qf.index, 0)
+		if arg := strings.TrimSpace(e.Arg); arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n <= 0 || len(qf.entries) < n {
+				return "", fmt.Errorf("invalid argument for %s: %s", e.CmdName, arg)
+			}
+			index = n - 1
+		}
+	}
+	if err := m.jumpTo(qf.window, qf.entries[index].offset); err != nil {
+		return "", err
+	}
+	qf.index = index
+	return fmt.Sprintf("(%d of %d): %s", index+1, len(qf.entries), qf.title), nil
+}
+
+// Quickfix returns the state of the quickfix list if it is opened.
+func (m *Manager) Quickfix() *state.QuickfixState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	qf, height := m.quickfix, m.quickfixHeight()
+	if height <= 0 {
+		return nil
+	}
+	rows := height - 1
+	if qf.index >= 0 {
+		if qf.index < qf.top {
+			qf.top = qf.index
+		} else if qf.index >= qf.top+rows {
+			qf.top = qf.index - rows + 1
+		}
+	}
+	qf.top = mathutil.MaxInt(0, mathutil.MinInt(qf.top, len(qf.entries)-rows))
+	s := &state.QuickfixState{
+		Title:   qf.title,
+		Top:     qf.top,
+		Index:   qf.index,
+		Total:   len(qf.entries),
+		Height:  height,
+		Running: qf.running,
+		Focused: qf.focus,
+	}
+	for _, entry := range qf.entries[qf.top:mathutil.MinInt(qf.top+rows, len(qf.entries))] {
+		text := entry.text
+		if text == "" {
+			text = qf.window.context(entry.offset)
+		}
+		s.Entries = append(s.Entries, state.QuickfixEntry{Offset: entry.offset, Text: text})
+	}
+	return s
+}
+
+// context returns the hex and the ASCII characters of the bytes at the offset.
+func (w *window) context(offset int64) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, bytes, err := w.readBytes(offset, quickfixContextSize)
+	if err != nil {
+		return err.Error()
+	}
+	var hex, text strings.Builder
+	for i, b := range bytes {
+		if i >= n {
+			hex.WriteString("   ")
+			continue
+		}
+		fmt.Fprintf(&hex, "%02x ", b)
+		if 0x20 <= b && b < 0x7f {
+			text.WriteByte(b)
+		} else {
+			text.WriteByte('.')
+		}
+	}
+	return hex.String() + " " + text.String()
+}
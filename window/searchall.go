@@ -0,0 +1,176 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/searcher"
+)
+
+// searchAll searches the pattern through the buffers from the cursor of the
+// current window, and moves to the first match. The buffers are searched in
+// the order of the buffer list, and the search continues at the other end
+// of the current buffer up to the cursor if wrapscan is set.
+func (m *Manager) searchAll(e event.Event, forward bool) error {
+	pattern, offset, err := searcher.SplitOffset(e.Arg, '/')
+	if err != nil {
+		return err
+	}
+	target, _, err := searcher.DecodeSearchPattern(pattern)
+	if err != nil {
+		return err
+	}
+	if len(target) == 0 {
+		return fmt.Errorf("empty pattern for %s", e.CmdName)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allSearch = true
+	if m.allSearchQuit != nil {
+		close(m.allSearchQuit)
+	}
+	quit := make(chan struct{})
+	wrapScan := m.options.WrapScan
+	current := m.windows[m.windowIndex]
+	windows := []*window{current}
+	for i, n := 1, len(m.windows); i < n; i++ {
+		j := m.windowIndex + i
+		if !forward {
+			j = m.windowIndex - i
+		}
+		if wrapScan {
+			j = (j + n) % n
+		}
+		if 0 <= j && j < n && m.windows[j] != nil {
+			windows = append(windows, m.windows[j])
+		}
+	}
+	if wrapScan {
+		windows = append(windows, current)
+	}
+	m.allSearchQuit, m.allSearchWindows = quit, windows
+	current.mu.Lock()
+	cursor := current.cursor
+	current.mu.Unlock()
+	go func() {
+		for i, window := range windows {
+			window.mu.Lock()
+			start, limit, length := cursor, int64(-1), window.length
+			if i > 0 {
+				if start = -1; !forward {
+					start = length
+				}
+				if window == current {
+					limit = cursor
+				}
+			}
+			r := window.buffer.Clone()
+			window.mu.Unlock()
+			x, err := m.waitSearchAll(r, start, limit, pattern, forward, quit)
+			if err != nil {
+				if searcher.IsNotFound(err) {
+					continue
+				}
+				m.finishSearchAll(quit, event.Event{Type: event.Info, Error: err})
+				return
+			}
+			if offset.End {
+				x += int64(len(target)) - 1
+			}
+			m.mu.Lock()
+			if m.allSearchQuit != quit {
+				m.mu.Unlock()
+				return
+			}
+			m.allSearchQuit, m.allSearchWindows = nil, nil
+			err = m.jumpTo(window, x+offset.N)
+			index := m.windowIndex
+			m.mu.Unlock()
+			ev := event.Event{Type: event.Redraw}
+			if err != nil {
+				ev = event.Event{Type: event.Error, Error: err}
+			} else if i > 0 {
+				ev = event.Event{Type: event.Info, Error: searchAllMessage(forward, i == len(windows)-1 && window == current, index)}
+			}
+			m.eventCh <- ev
+			return
+		}
+		err := errors.New("pattern not found: " + pattern)
+		if !wrapScan && forward {
+			err = fmt.Errorf("search hit BOTTOM without match for: %s", pattern)
+		} else if !wrapScan {
+			err = fmt.Errorf("search hit TOP without match for: %s", pattern)
+		}
+		m.finishSearchAll(quit, event.Event{Type: event.Info, Error: err})
+	}()
+	return nil
+}
+
+// waitSearchAll searches the pattern in the snapshot of the buffer, and waits
+// for the result. The search stops at the limit unless it is negative.
+func (m *Manager) waitSearchAll(
+	r io.ReaderAt, start, limit int64, pattern string, forward bool, quit <-chan struct{},
+) (int64, error) {
+	s := searcher.NewSearcher(r)
+	var ch <-chan interface{}
+	if limit < 0 {
+		ch = s.Search(start, pattern, forward)
+	} else {
+		ch = s.SearchUntil(start, limit, pattern, forward)
+	}
+	for {
+		select {
+		case x, ok := <-ch:
+			if !ok {
+				return 0, errors.New("search is aborted")
+			}
+			switch x := x.(type) {
+			case int64:
+				return x, nil
+			case error:
+				return 0, x
+			}
+		case <-quit:
+			return 0, s.Abort()
+		}
+	}
+}
+
+func (m *Manager) finishSearchAll(quit chan struct{}, e event.Event) {
+	m.mu.Lock()
+	if m.allSearchQuit != quit {
+		m.mu.Unlock()
+		return
+	}
+	m.allSearchQuit, m.allSearchWindows = nil, nil
+	m.mu.Unlock()
+	m.eventCh <- e
+}
+
+func searchAllMessage(forward, wrapped bool, index int) error {
+	switch {
+	case forward && wrapped:
+		return errors.New("search hit BOTTOM, continuing at TOP")
+	case wrapped:
+		return errors.New("search hit TOP, continuing at BOTTOM")
+	case forward:
+		return fmt.Errorf("search hit BOTTOM, continuing in buffer %d", index+1)
+	default:
+		return fmt.Errorf("search hit TOP, continuing in buffer %d", index+1)
+	}
+}
+
+func (m *Manager) abortSearchAll() bool {
+	m.mu.Lock()
+	if m.allSearchQuit == nil {
+		m.mu.Unlock()
+		return false
+	}
+	close(m.allSearchQuit)
+	m.allSearchQuit, m.allSearchWindows = nil, nil
+	m.mu.Unlock()
+	m.eventCh <- event.Event{Type: event.Info, Error: errors.New("search is aborted")}
+	return true
+}
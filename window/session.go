@@ -0,0 +1,219 @@
+package window
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/itchyny/bed/display"
+	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/layout"
+	"github.com/itchyny/bed/mathutil"
+	"github.com/itchyny/bed/option"
+	"github.com/itchyny/bed/pathutil"
+)
+
+// session is saved by mksession and restored by OpenSession.
+// The buffers keep the numbers so the deleted buffers are saved as null.
+type session struct {
+	Buffers  []*sessionBuffer `json:"buffers"`
+	Tabs     []sessionTab     `json:"tabs"`
+	TabIndex int              `json:"tabIndex"`
+	Options  []string         `json:"options,omitempty"`
+}
+
+type sessionBuffer struct {
+	Filename  string           `json:"filename"`
+	Cursor    int64            `json:"cursor"`
+	Offset    int64            `json:"offset"`
+	Marks     map[string]int64 `json:"marks,omitempty"`
+	FocusText bool             `json:"focusText,omitempty"`
+	Display   string           `json:"display,omitempty"`
+}
+
+type sessionTab struct {
+	Layout          json.RawMessage `json:"layout"`
+	WindowIndex     int             `json:"windowIndex"`
+	PrevWindowIndex int             `json:"prevWindowIndex"`
+}
+
+// mksession writes the session to the file.
+func (m *Manager) mksession(e event.Event) error {
+	if e.Arg == "" {
+		return fmt.Errorf("an argument is required for %s", e.CmdName)
+	}
+	name, err := pathutil.HomedirExpand(e.Arg)
+	if err != nil {
+		return err
+	}
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !e.Bang {
+		flag |= os.O_EXCL
+	}
+	m.mu.Lock()
+	s, err := m.session()
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	bs, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(name, flag, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%s exists, use %s! to overwrite", name, e.CmdName)
+		}
+		return err
+	}
+	defer f.Close()
+	if _, err = f.Write(append(bs, '\n')); err != nil {
+		return err
+	}
+	m.eventCh <- event.Event{Type: event.Info, Error: fmt.Errorf("session saved to %s", name)}
+	return nil
+}
+
+func (m *Manager) session() (*session, error) {
+	s := &session{TabIndex: m.tabIndex, Options: m.options.Changed()}
+	for _, window := range m.windows {
+		if window == nil {
+			s.Buffers = append(s.Buffers, nil)
+			continue
+		}
+		b, err := window.session()
+		if err != nil {
+			return nil, err
+		}
+		s.Buffers = append(s.Buffers, b)
+	}
+	for i, t := range m.tabs {
+		if i == m.tabIndex {
+			t = tab{m.layout, m.windowIndex, m.prevWindowIndex}
+		}
+		bs, err := json.Marshal(t.layout)
+		if err != nil {
+			return nil, err
+		}
+		s.Tabs = append(s.Tabs, sessionTab{bs, t.windowIndex, t.prevWindowIndex})
+	}
+	return s, nil
+}
+
+func (w *window) session() (*sessionBuffer, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := &sessionBuffer{
+		Cursor:    w.cursor,
+		Offset:    w.offset,
+		FocusText: w.focusText,
+		Display:   w.display.Name,
+	}
+	if w.filename != "" {
+		var err error
+		if b.Filename, err = filepath.Abs(w.filename); err != nil {
+			return nil, err
+		}
+	}
+	if len(w.marks) > 0 {
+		b.Marks = make(map[string]int64, len(w.marks))
+		for name, offset := range w.marks {
+			b.Marks[string(name)] = offset
+		}
+	}
+	return b, nil
+}
+
+// OpenSession restores the session saved by mksession.
+// This is called on starting the editor instead of Open.
+func (m *Manager) OpenSession(name string) error {
+	bs, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	var s session
+	if err := json.Unmarshal(bs, &s); err != nil {
+		return fmt.Errorf("invalid session file: %s: %w", name, err)
+	}
+	if len(s.Tabs) == 0 || s.TabIndex < 0 || len(s.Tabs) <= s.TabIndex {
+		return fmt.Errorf("invalid session file: %s", name)
+	}
+	options := option.Default()
+	for _, arg := range s.Options {
+		if _, err := options.Set(arg); err != nil {
+			return fmt.Errorf("invalid session file: %s: %w", name, err)
+		}
+	}
+	tabs := make([]tab, len(s.Tabs))
+	for i, t := range s.Tabs {
+		l, err := layout.Unmarshal(t.Layout)
+		if err != nil {
+			return fmt.Errorf("invalid session file: %s: %w", name, err)
+		}
+		indices := []int{t.WindowIndex, t.PrevWindowIndex}
+		for index := range l.Collect() {
+			indices = append(indices, index)
+		}
+		for _, index := range indices {
+			if index < 0 || len(s.Buffers) <= index || s.Buffers[index] == nil {
+				return fmt.Errorf("invalid session file: %s", name)
+			}
+		}
+		if countActive(l) != 1 || l.ActiveWindow().Index != t.WindowIndex {
+			return fmt.Errorf("invalid session file: %s", name)
+		}
+		tabs[i] = tab{l, t.WindowIndex, t.PrevWindowIndex}
+	}
+	m.SetOptions(options)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	windows := make([]*window, len(s.Buffers))
+	for i, b := range s.Buffers {
+		if b == nil {
+			continue
+		}
+		if windows[i], err = m.open(b.Filename); err != nil {
+			return err
+		}
+		windows[i].restore(b)
+	}
+	m.windows, m.tabs, m.tabIndex = windows, tabs, s.TabIndex
+	t := tabs[s.TabIndex]
+	m.layout, m.windowIndex, m.prevWindowIndex = t.layout, t.windowIndex, t.prevWindowIndex
+	m.layout = m.resizeLayout(m.layout)
+	return nil
+}
+
+// countActive returns the number of the active windows in the layout.
+func countActive(l layout.Layout) int {
+	switch l := l.(type) {
+	case layout.Window:
+		if l.Active {
+			return 1
+		}
+	case layout.Horizontal:
+		return countActive(l.Top) + countActive(l.Bottom)
+	case layout.Vertical:
+		return countActive(l.Left) + countActive(l.Right)
+	}
+	return 0
+}
+
+func (w *window) restore(b *sessionBuffer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cursor = mathutil.MaxInt64(mathutil.MinInt64(b.Cursor, w.length-1), 0)
+	w.offset = mathutil.MaxInt64(mathutil.MinInt64(b.Offset, w.cursor), 0)
+	for name, offset := range b.Marks {
+		if r := []rune(name); len(r) == 1 {
+			w.marks[r[0]] = offset
+		}
+	}
+	w.focusText = b.FocusText
+	if d, err := display.Lookup(b.Display); err == nil {
+		w.display = d
+	}
+}
@@ -0,0 +1,151 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/itchyny/bed/event"
+	"github.com/itchyny/bed/layout"
+	"github.com/itchyny/bed/state"
+)
+
+func (m *Manager) resizeLayout(l layout.Layout) layout.Layout {
+	height := m.height - m.quickfixHeight()
+	if len(m.tabs) > 1 {
+		return l.Resize(0, 1, m.width, height-1) // tab line
+	}
+	return l.Resize(0, 0, m.width, height)
+}
+
+func (m *Manager) switchTab(index int) {
+	m.tabs[m.tabIndex] = tab{m.layout, m.windowIndex, m.prevWindowIndex}
+	m.tabIndex = index
+	t := m.tabs[index]
+	m.layout, m.windowIndex, m.prevWindowIndex = t.layout, t.windowIndex, t.prevWindowIndex
+	m.layout = m.resizeLayout(m.layout)
+}
+
+func (m *Manager) tabNew(e event.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	window, err := m.open(e.Arg)
+	if err != nil {
+		return err
+	}
+	m.tabs[m.tabIndex] = tab{m.layout, m.windowIndex, m.prevWindowIndex}
+	m.addWindow(window)
+	m.tabIndex++
+	m.tabs = append(m.tabs[:m.tabIndex], append([]tab{{}}, m.tabs[m.tabIndex:]...)...)
+	m.layout = m.resizeLayout(layout.NewLayout(m.windowIndex))
+	return nil
+}
+
+func (m *Manager) tabClose(e event.Event) error {
+	if len(e.Arg) > 0 {
+		return fmt.Errorf("too many arguments for %s", e.CmdName)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.tabs) == 1 {
+		return errors.New("cannot close last tab page")
+	}
+	m.closeTab()
+	return nil
+}
+
+func (m *Manager) closeTab() {
+	index := m.tabIndex
+	m.tabs = append(m.tabs[:index], m.tabs[index+1:]...)
+	if index == len(m.tabs) {
+		index--
+	}
+	t := m.tabs[index]
+	m.tabIndex = index
+	m.layout, m.windowIndex, m.prevWindowIndex = t.layout, t.windowIndex, t.prevWindowIndex
+	m.layout = m.resizeLayout(m.layout)
+}
+
+// tabNext goes to the next tab page, or the tab page of the count.
+func (m *Manager) tabNext(e event.Event) error {
+	if e.Arg != "" {
+		n, err := strconv.ParseInt(e.Arg, 10, 64)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid argument for %s: %s", e.CmdName, e.Arg)
+		}
+		e.Count = n
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e.Count > int64(len(m.tabs)) {
+		return fmt.Errorf("invalid tab page number: %d", e.Count)
+	}
+	if e.Count > 0 {
+		m.switchTab(int(e.Count) - 1)
+	} else {
+		m.switchTab((m.tabIndex + 1) % len(m.tabs))
+	}
+	return nil
+}
+
+// tabPrevious goes to the previous tab page count times.
+func (m *Manager) tabPrevious(e event.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := int(e.Count % int64(len(m.tabs)))
+	if e.Count == 0 {
+		count = 1
+	}
+	m.switchTab((m.tabIndex - count + len(m.tabs)) % len(m.tabs))
+}
+
+// tabMove moves the current tab page to after the tab page of the argument.
+// Moves to the last if no argument is specified, to the first with 0.
+func (m *Manager) tabMove(e event.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	index := len(m.tabs) - 1
+	if arg := strings.TrimSpace(e.Arg); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid argument for %s: %s", e.CmdName, arg)
+		}
+		if arg[0] == '+' || arg[0] == '-' {
+			index = m.tabIndex + n
+		} else {
+			if index = n; n > m.tabIndex {
+				index--
+			}
+		}
+		if index < 0 || len(m.tabs) <= index {
+			return fmt.Errorf("invalid argument for %s: %s", e.CmdName, arg)
+		}
+	}
+	t := tab{m.layout, m.windowIndex, m.prevWindowIndex}
+	m.tabs = append(m.tabs[:m.tabIndex], m.tabs[m.tabIndex+1:]...)
+	m.tabs = append(m.tabs[:index], append([]tab{t}, m.tabs[index:]...)...)
+	m.tabIndex = index
+	return nil
+}
+
+// Tabs returns the states of the tab pages.
+func (m *Manager) Tabs() ([]state.TabState, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tabs := make([]state.TabState, len(m.tabs))
+	for i, t := range m.tabs {
+		if i == m.tabIndex {
+			t = tab{m.layout, m.windowIndex, m.prevWindowIndex}
+		}
+		window := m.windows[t.windowIndex]
+		window.mu.Lock()
+		tabs[i] = state.TabState{
+			Name:        window.name,
+			Modified:    window.changedTick != window.savedChangedTick,
+			WindowCount: len(t.layout.Collect()),
+		}
+		window.mu.Unlock()
+	}
+	return tabs, m.tabIndex
+}
@@ -1,18 +1,23 @@
 package window
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"strconv"
+	"strings"
 	"sync"
-	"unicode/utf8"
 
 	"github.com/itchyny/bed/buffer"
+	"github.com/itchyny/bed/charset"
+	"github.com/itchyny/bed/display"
 	"github.com/itchyny/bed/event"
 	"github.com/itchyny/bed/history"
 	"github.com/itchyny/bed/mathutil"
 	"github.com/itchyny/bed/mode"
+	"github.com/itchyny/bed/option"
 	"github.com/itchyny/bed/searcher"
 	"github.com/itchyny/bed/state"
 )
@@ -26,6 +31,17 @@ type window struct {
 	history          *history.History
 	searcher         *searcher.Searcher
 	searchTick       uint64
+	searchProgress   int
+	wrapScan         bool
+	incSearching     bool
+	incSearchTick    uint64
+	incSearchPos     position
+	matchStart       int64
+	matchEnd         int64
+	filterCancel     context.CancelFunc
+	filterTick       uint64
+	hashCancel       context.CancelFunc
+	checksumFields   []*checksumField
 	filename         string
 	name             string
 	height           int64
@@ -34,11 +50,17 @@ type window struct {
 	cursor           int64
 	length           int64
 	stack            []position
+	marks            map[rune]int64
 	append           bool
 	replaceByte      bool
 	extending        bool
-	pending          bool
+	pendingDigits    int
 	pendingByte      byte
+	literal          rune
+	literalDigits    string
+	encoding         string
+	offsetOrigin     int64
+	display          display.Display
 	visualStart      int64
 	focusText        bool
 	redrawCh         chan<- struct{}
@@ -65,19 +87,32 @@ func newWindow(r readAtSeeker, filename string, name string, eventCh chan<- even
 	history := history.NewHistory()
 	history.Push(buffer, 0, 0, 0)
 	return &window{
-		buffer:      buffer,
-		history:     history,
-		searcher:    searcher.NewSearcher(r),
-		filename:    filename,
-		name:        name,
-		length:      length,
-		visualStart: -1,
-		redrawCh:    redrawCh,
-		eventCh:     eventCh,
-		mu:          new(sync.Mutex),
+		buffer:         buffer,
+		history:        history,
+		searcher:       searcher.NewSearcher(r),
+		filename:       filename,
+		name:           name,
+		length:         length,
+		marks:          make(map[rune]int64),
+		encoding:       "utf-8",
+		wrapScan:       true,
+		searchProgress: -1,
+		display:        display.Default(),
+		visualStart:    -1,
+		redrawCh:       redrawCh,
+		eventCh:        eventCh,
+		mu:             new(sync.Mutex),
 	}, nil
 }
 
+func (w *window) setOptions(options option.Options) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.encoding = options.Encoding
+	w.offsetOrigin = options.OffsetOrigin
+	w.wrapScan = options.WrapScan
+}
+
 func (w *window) setSize(width, height int) {
 	w.width, w.height = int64(width), int64(height)
 	w.offset = w.offset / w.width * w.width
@@ -114,7 +149,9 @@ func (w *window) emit(e event.Event) {
 	case event.CursorEnd:
 		w.cursorEnd(e.Count)
 	case event.CursorGoto:
-		w.cursorGoto(e)
+		if err := w.cursorGoto(e); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		}
 	case event.ScrollUp:
 		w.scrollUp(e.Count)
 	case event.ScrollDown:
@@ -153,6 +190,8 @@ func (w *window) emit(e event.Event) {
 		w.jumpTo()
 	case event.JumpBack:
 		w.jumpBack()
+	case event.SetMark:
+		w.setMark(e.Rune)
 
 	case event.DeleteByte:
 		newEvent = event.Event{Type: event.Copied, Buffer: w.deleteBytes(e.Count), Arg: "deleted"}
@@ -190,9 +229,15 @@ func (w *window) emit(e event.Event) {
 	case event.ExitInsert:
 		w.exitInsert()
 	case event.Rune:
-		if w.insertRune(e.Mode, e.Rune) {
+		if w.literal != 0 {
+			newEvent = w.insertLiteral(e.Mode, e.Rune)
+		} else if exitInsert, err := w.insertRune(e.Mode, e.Rune); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		} else if exitInsert {
 			newEvent = event.Event{Type: event.ExitInsert}
 		}
+	case event.StartLiteral:
+		w.startLiteral()
 	case event.Backspace:
 		w.backspace(e.Mode)
 	case event.Delete:
@@ -205,10 +250,8 @@ func (w *window) emit(e event.Event) {
 		w.exitVisual()
 	case event.SwitchFocus:
 		w.focusText = !w.focusText
-		if w.pending {
-			w.pending = false
-			w.pendingByte = '\x00'
-		}
+		w.literal = 0
+		w.pendingDigits, w.pendingByte = 0, '\x00'
 	case event.Undo:
 		if e.Mode != mode.Normal {
 			panic("event.Undo should be emitted under normal mode")
@@ -225,14 +268,68 @@ func (w *window) emit(e event.Event) {
 		newEvent = event.Event{Type: event.Copied, Buffer: w.cut(), Arg: "deleted"}
 	case event.Paste, event.PastePrev:
 		newEvent = event.Event{Type: event.Pasted, Count: w.paste(e)}
+	case event.Fill:
+		if n, err := w.fill(e); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		} else if e.Bang {
+			newEvent = event.Event{Type: event.Info, Error: fmt.Errorf("%d (0x%x) bytes inserted", n, n)}
+		} else {
+			newEvent = event.Event{Type: event.Info, Error: fmt.Errorf("%d (0x%x) bytes filled", n, n)}
+		}
+	case event.Filter:
+		if err := w.filter(e); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		}
+	case event.Hash:
+		if err := w.hash(e); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		}
+	case event.ChecksumFix:
+		if msg, err := w.checksumFix(e); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		} else {
+			newEvent = event.Event{Type: event.Info, Error: errors.New(msg)}
+		}
+	case event.Display:
+		if str, err := w.setDisplay(e); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		} else if str != "" {
+			newEvent = event.Event{Type: event.Info, Error: errors.New(str)}
+		}
+	case event.Filtered:
+		if n, err := w.filtered(e); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		} else {
+			newEvent = event.Event{Type: event.Info, Error: fmt.Errorf("%d (0x%x) bytes filtered", n, n)}
+		}
+	case event.IncrementalSearch:
+		w.incSearch(e.Arg, e.Rune, e.Rune == '/')
+	case event.ExitCmdline:
+		w.exitIncSearch()
 	case event.ExecuteSearch:
-		w.search(e.Arg, e.Rune == '/')
+		w.exitIncSearch()
+		if err := w.search(e.Arg, e.Rune, e.Rune == '/'); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		}
 	case event.NextSearch:
-		w.search(e.Arg, e.Rune == '/')
+		if err := w.search(e.Arg, e.Rune, e.Rune == '/'); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		}
 	case event.PreviousSearch:
-		w.search(e.Arg, e.Rune != '/')
+		if err := w.search(e.Arg, e.Rune, e.Rune != '/'); err != nil {
+			newEvent = event.Event{Type: event.Error, Error: err}
+		}
 	case event.AbortSearch:
-		w.abortSearch()
+		if w.filterCancel != nil || w.hashCancel != nil {
+			if w.filterCancel != nil {
+				w.filterCancel()
+			}
+			if w.hashCancel != nil {
+				w.hashCancel()
+			}
+		} else {
+			w.abortSearch()
+		}
 	default:
 		w.mu.Unlock()
 		return
@@ -290,11 +387,13 @@ func (w *window) writeTo(r *event.Range, dst io.Writer) (int64, error) {
 	return io.Copy(dst, io.LimitReader(w.buffer, to-from+1))
 }
 
+// positionToOffset converts the position to the offset. The absolute positions
+// and the expressions are the addresses relative to the offset origin.
 func (w *window) positionToOffset(pos event.Position) (int64, error) {
 	switch pos := pos.(type) {
 	case event.Absolute:
 		return mathutil.MaxInt64(
-			mathutil.MinInt64(pos.Offset, mathutil.MaxInt64(w.length, 1)-1),
+			mathutil.MinInt64(pos.Offset-w.offsetOrigin, mathutil.MaxInt64(w.length, 1)-1),
 			0,
 		), nil
 	case event.Relative:
@@ -324,6 +423,15 @@ func (w *window) positionToOffset(pos event.Position) (int64, error) {
 			mathutil.MinInt64(pos.Offset, mathutil.MaxInt64(w.length, 1)-1-w.cursor),
 			-w.cursor,
 		), nil
+	case event.Expression:
+		offset, err := pos.Eval(exprEnv{w})
+		if err != nil {
+			return 0, err
+		}
+		return mathutil.MaxInt64(
+			mathutil.MinInt64(offset-w.offsetOrigin, mathutil.MaxInt64(w.length, 1)-1),
+			0,
+		), nil
 	default:
 		return 0, errors.New("invalid range")
 	}
@@ -338,19 +446,25 @@ func (w *window) state(width, height int) (*state.WindowState, error) {
 		return nil, err
 	}
 	return &state.WindowState{
-		Name:          w.name,
-		Modified:      w.changedTick != w.savedChangedTick,
-		Width:         int(w.width),
-		Offset:        w.offset,
-		Cursor:        w.cursor,
-		Bytes:         bytes,
-		Size:          n,
-		Length:        w.length,
-		Pending:       w.pending,
-		PendingByte:   w.pendingByte,
-		VisualStart:   w.visualStart,
-		EditedIndices: w.buffer.EditedIndices(),
-		FocusText:     w.focusText,
+		Name:           w.name,
+		Modified:       w.changedTick != w.savedChangedTick,
+		Width:          int(w.width),
+		Offset:         w.offset,
+		Cursor:         w.cursor,
+		Bytes:          bytes,
+		Size:           n,
+		Length:         w.length,
+		Pending:        w.pendingDigits > 0,
+		PendingByte:    w.pendingByte,
+		PendingDigits:  w.pendingDigits,
+		Display:        w.display,
+		VisualStart:    w.visualStart,
+		MatchStart:     w.matchStart,
+		MatchEnd:       w.matchEnd,
+		Searching:      w.searchProgress >= 0,
+		SearchProgress: w.searchProgress,
+		EditedIndices:  w.buffer.EditedIndices(),
+		FocusText:      w.focusText,
 	}, nil
 }
 
@@ -491,26 +605,30 @@ func (w *window) cursorEnd(count int64) {
 	)
 }
 
-func (w *window) cursorGoto(e event.Event) {
+func (w *window) cursorGoto(e event.Event) error {
 	if e.Range != nil {
 		if e.Range.To != nil {
-			w.cursorGotoPos(e.Range.To)
+			return w.cursorGotoPos(e.Range.To)
 		} else if e.Range.From != nil {
-			w.cursorGotoPos(e.Range.From)
+			return w.cursorGotoPos(e.Range.From)
 		}
 	}
+	return nil
 }
 
-func (w *window) cursorGotoPos(pos event.Position) {
-	if offset, err := w.positionToOffset(pos); err == nil {
-		w.cursor = mathutil.MaxInt64(mathutil.MinInt64(offset, mathutil.MaxInt64(w.length, 1)-1), 0)
-		if w.cursor < w.offset {
-			w.offset = (mathutil.MaxInt64(w.cursor/w.width, w.height/2) - w.height/2) * w.width
-		} else if w.cursor >= w.offset+w.height*w.width {
-			h := (mathutil.MaxInt64(w.length, 1)+w.width-1)/w.width - w.height
-			w.offset = mathutil.MinInt64((w.cursor-w.height*w.width+w.width)/w.width+w.height/2, h) * w.width
-		}
+func (w *window) cursorGotoPos(pos event.Position) error {
+	offset, err := w.positionToOffset(pos)
+	if err != nil {
+		return err
 	}
+	w.cursor = mathutil.MaxInt64(mathutil.MinInt64(offset, mathutil.MaxInt64(w.length, 1)-1), 0)
+	if w.cursor < w.offset {
+		w.offset = (mathutil.MaxInt64(w.cursor/w.width, w.height/2) - w.height/2) * w.width
+	} else if w.cursor >= w.offset+w.height*w.width {
+		h := (mathutil.MaxInt64(w.length, 1)+w.width-1)/w.width - w.height
+		w.offset = mathutil.MinInt64((w.cursor-w.height*w.width+w.width)/w.width+w.height/2, h) * w.width
+	}
+	return nil
 }
 
 func (w *window) scrollUp(count int64) {
@@ -695,6 +813,12 @@ func (w *window) jumpBack() {
 	w.stack = w.stack[:len(w.stack)-1]
 }
 
+func (w *window) setMark(name rune) {
+	if 'a' <= name && name <= 'z' {
+		w.marks[name] = w.cursor
+	}
+}
+
 func (w *window) deleteBytes(count int64) *buffer.Buffer {
 	if w.length == 0 {
 		return nil
@@ -781,10 +905,29 @@ func (w *window) showDecimal() string {
 	return fmt.Sprintf("%d", bytes[0])
 }
 
+// setDisplay sets the display of the hex column,
+// or returns the name of the display without the argument.
+func (w *window) setDisplay(e event.Event) (string, error) {
+	args := strings.Fields(e.Arg)
+	switch len(args) {
+	case 0:
+		return w.display.Name, nil
+	case 1:
+		d, err := display.Lookup(args[0])
+		if err != nil {
+			return "", err
+		}
+		w.display, w.pendingDigits, w.pendingByte = d, 0, '\x00'
+		return "", nil
+	default:
+		return "", fmt.Errorf("too many arguments for %s", e.CmdName)
+	}
+}
+
 func (w *window) startInsert() {
 	w.append = false
 	w.extending = false
-	w.pending = false
+	w.pendingDigits = 0
 	if w.cursor == w.length {
 		w.append = true
 		w.extending = true
@@ -796,7 +939,7 @@ func (w *window) startInsertHead() {
 	w.cursorHead(0)
 	w.append = false
 	w.extending = false
-	w.pending = false
+	w.pendingDigits = 0
 	if w.cursor == w.length {
 		w.append = true
 		w.extending = true
@@ -807,7 +950,7 @@ func (w *window) startInsertHead() {
 func (w *window) startAppend() {
 	w.append = true
 	w.extending = false
-	w.pending = false
+	w.pendingDigits = 0
 	if w.length > 0 {
 		w.cursor++
 	}
@@ -826,18 +969,19 @@ func (w *window) startReplaceByte() {
 	w.replaceByte = true
 	w.append = false
 	w.extending = false
-	w.pending = false
+	w.pendingDigits = 0
 }
 
 func (w *window) startReplace() {
 	w.replaceByte = false
 	w.append = true
 	w.extending = false
-	w.pending = false
+	w.pendingDigits = 0
 }
 
 func (w *window) exitInsert() {
-	w.pending = false
+	w.pendingDigits = 0
+	w.literal = 0
 	if w.append {
 		if w.extending && w.length > 0 {
 			w.length--
@@ -848,74 +992,166 @@ func (w *window) exitInsert() {
 		w.replaceByte = false
 		w.append = false
 		w.extending = false
-		w.pending = false
+		w.pendingDigits = 0
 	}
 	w.buffer.Flush()
 }
 
-func (w *window) insertRune(m mode.Mode, ch rune) (exitInsert bool) {
+func (w *window) insertRune(m mode.Mode, ch rune) (exitInsert bool, err error) {
 	if m == mode.Insert || m == mode.Replace {
 		if w.focusText {
-			buf := make([]byte, 4)
-			n := utf8.EncodeRune(buf, ch)
-			for i := 0; i < n; i++ {
-				exitInsert = exitInsert || w.insertByte(m, byte(buf[i]>>4))
-				exitInsert = exitInsert || w.insertByte(m, byte(buf[i]&0x0f))
+			var bs []byte
+			if bs, err = charset.Encode(w.encoding, ch); err != nil {
+				return
 			}
-		} else if '0' <= ch && ch <= '9' {
-			exitInsert = w.insertByte(m, byte(ch-'0'))
-		} else if 'a' <= ch && ch <= 'f' {
-			exitInsert = w.insertByte(m, byte(ch-'a'+0x0a))
+			exitInsert = w.insertBytes(m, bs)
+		} else if b, ok := w.display.Digit(ch); ok {
+			exitInsert = w.insertByte(m, b)
 		}
 	}
 	return
 }
 
-func (w *window) insertByte(m mode.Mode, b byte) bool {
-	if w.pending {
-		switch m {
-		case mode.Insert:
-			w.insert(w.cursor, w.pendingByte|b)
-			w.cursor++
-			w.length++
-		case mode.Replace:
-			if w.visualStart >= 0 && w.replaceByte {
-				start, end := w.visualStart, w.cursor
-				if start > end {
-					start, end = end, start
+func (w *window) insertBytes(m mode.Mode, bs []byte) (exitInsert bool) {
+	for _, b := range bs {
+		exitInsert = exitInsert || w.putByte(m, b)
+	}
+	return
+}
+
+// literalBases holds the base and the maximum number of digits
+// of the byte literal input in insert mode; <C-v>d255, <C-v>x41.
+var literalBases = map[rune]struct{ base, digits int }{
+	'd': {10, 3},
+	'o': {8, 3},
+	'b': {2, 8},
+	'x': {16, 2},
+}
+
+func (w *window) startLiteral() {
+	w.pendingDigits, w.pendingByte = 0, '\x00'
+	w.literal, w.literalDigits = 'v', ""
+}
+
+func (w *window) insertLiteral(m mode.Mode, ch rune) event.Event {
+	if w.literal == 'v' {
+		if _, ok := literalBases[ch]; ok {
+			w.literal = ch
+			return event.Event{}
+		}
+		switch {
+		case ch == 'e': // cycle the encoding option, which is applied to all the windows
+			w.literal = 0
+			for i, enc := range charset.Names {
+				if enc == w.encoding {
+					w.encoding = charset.Names[(i+1)%len(charset.Names)]
+					break
 				}
-				w.replaceIn(start, end+1, w.pendingByte|b)
-				w.visualStart = -1
-				return true
 			}
-			w.replace(w.cursor, w.pendingByte|b)
-			if w.length == 0 {
-				w.length++
+			return event.Event{Type: event.Set, Arg: "encoding=" + w.encoding + " encoding?"}
+		case '0' <= ch && ch <= '9':
+			w.literal = 'd'
+		default: // insert the character as is even in the hex column
+			w.literal = 0
+			bs, err := charset.Encode(w.encoding, ch)
+			if err != nil {
+				return event.Event{Type: event.Error, Error: err}
 			}
-			if w.replaceByte {
-				w.exitInsert()
-				return true
+			if w.insertBytes(m, bs) {
+				return event.Event{Type: event.ExitInsert}
 			}
-			w.cursor++
-			if w.cursor == w.length {
-				w.append = true
-				w.extending = true
-				w.length++
+			return event.Event{}
+		}
+	}
+	b := literalBases[w.literal]
+	if _, err := strconv.ParseUint(string(ch), b.base, 8); err == nil {
+		if w.literalDigits += string(ch); len(w.literalDigits) < b.digits {
+			return event.Event{}
+		}
+		ch = 0
+	}
+	literal, digits := w.literal, w.literalDigits
+	w.literal, w.literalDigits = 0, ""
+	if digits != "" {
+		n, err := strconv.ParseUint(digits, b.base, 8)
+		if err != nil {
+			return event.Event{Type: event.Error, Error: fmt.Errorf("invalid byte literal: %c%s", literal, digits)}
+		}
+		if w.putByte(m, byte(n)) {
+			return event.Event{Type: event.ExitInsert}
+		}
+	}
+	if ch != 0 {
+		if exitInsert, err := w.insertRune(m, ch); err != nil {
+			return event.Event{Type: event.Error, Error: err}
+		} else if exitInsert {
+			return event.Event{Type: event.ExitInsert}
+		}
+	}
+	return event.Event{}
+}
+
+// insertByte inputs the digit of the byte in the display. The byte is put
+// when all the digits are input, and the digit overflowing the byte is ignored.
+func (w *window) insertByte(m mode.Mode, b byte) bool {
+	value := int(b) * w.display.Place(w.pendingDigits)
+	if w.pendingDigits > 0 {
+		value += int(w.pendingByte)
+	}
+	if value > 0xff {
+		return false
+	}
+	if w.pendingDigits+1 < w.display.Digits() {
+		w.pendingDigits, w.pendingByte = w.pendingDigits+1, byte(value)
+		return false
+	}
+	w.pendingDigits, w.pendingByte = 0, '\x00'
+	return w.putByte(m, byte(value))
+}
+
+func (w *window) putByte(m mode.Mode, b byte) bool {
+	switch m {
+	case mode.Insert:
+		w.insert(w.cursor, b)
+		w.cursor++
+		w.length++
+	case mode.Replace:
+		if w.visualStart >= 0 && w.replaceByte {
+			start, end := w.visualStart, w.cursor
+			if start > end {
+				start, end = end, start
 			}
+			w.replaceIn(start, end+1, b)
+			w.visualStart = -1
+			return true
+		}
+		w.replace(w.cursor, b)
+		if w.length == 0 {
+			w.length++
+		}
+		if w.replaceByte {
+			w.exitInsert()
+			return true
+		}
+		w.cursor++
+		if w.cursor == w.length {
+			w.append = true
+			w.extending = true
+			w.length++
 		}
-		w.pending = false
-		w.pendingByte = '\x00'
-	} else {
-		w.pending = true
-		w.pendingByte = b << 4
 	}
 	return false
 }
 
 func (w *window) backspace(m mode.Mode) {
-	if w.pending {
-		w.pending = false
-		w.pendingByte = '\x00'
+	if w.literal != 0 {
+		if w.literalDigits != "" {
+			w.literalDigits = w.literalDigits[:len(w.literalDigits)-1]
+		} else {
+			w.literal = 0
+		}
+	} else if w.pendingDigits > 0 {
+		w.pendingDigits, w.pendingByte = 0, '\x00'
 	} else if m == mode.Replace {
 		if w.cursor > 0 {
 			w.cursor--
@@ -1006,29 +1242,269 @@ func (w *window) paste(e event.Event) int64 {
 	return l * count
 }
 
-func (w *window) search(str string, forward bool) {
+func (w *window) fill(e event.Event) (int64, error) {
+	args := strings.Fields(e.Arg)
+	if len(args) == 0 {
+		return 0, fmt.Errorf("an argument is required for %s", e.CmdName)
+	}
+	var newBuffer func(int64) *buffer.Buffer
+	size := int64(1)
+	switch args[0] {
+	case "random":
+		if len(args) > 1 {
+			return 0, fmt.Errorf("too many arguments for %s", e.CmdName)
+		}
+		seed := rand.Uint64()
+		newBuffer = func(size int64) *buffer.Buffer {
+			return buffer.NewRandomBuffer(seed, size)
+		}
+	case "inc", "increment":
+		if len(args) > 2 {
+			return 0, fmt.Errorf("too many arguments for %s", e.CmdName)
+		}
+		var start uint64
+		if len(args) > 1 {
+			var err error
+			if start, err = strconv.ParseUint(args[1], 0, 8); err != nil {
+				return 0, fmt.Errorf("invalid start byte for %s: %s", e.CmdName, args[1])
+			}
+		}
+		newBuffer = func(size int64) *buffer.Buffer {
+			return buffer.NewIncrementBuffer(byte(start), size)
+		}
+	default:
+		pattern, err := searcher.DecodePattern(e.Arg)
+		if err != nil {
+			return 0, err
+		}
+		if len(pattern) == 0 {
+			return 0, fmt.Errorf("empty pattern for %s", e.CmdName)
+		}
+		newBuffer = func(size int64) *buffer.Buffer {
+			return buffer.NewPatternBuffer(pattern, size)
+		}
+		size = int64(len(pattern))
+	}
+	start := w.cursor
+	if e.Range != nil {
+		from, err := w.positionToOffset(e.Range.From)
+		if err != nil {
+			return 0, err
+		}
+		start = from
+		if e.Range.To != nil {
+			to, err := w.positionToOffset(e.Range.To)
+			if err != nil {
+				return 0, err
+			}
+			if from > to {
+				from, to = to, from
+			}
+			start, size = from, to-from+1
+		}
+	}
+	if e.Bang {
+		w.buffer.Paste(start, newBuffer(size))
+	} else {
+		w.buffer.Overwrite(start, newBuffer(size))
+	}
+	w.length, _ = w.buffer.Len()
+	w.cursor = mathutil.MinInt64(start, mathutil.MaxInt64(w.length, 1)-1)
+	w.updateTick()
+	return size, nil
+}
+
+// read inserts or overwrites the buffer of the event as an undoable change.
+// This is called by the manager so that it can close the file on errors.
+func (w *window) read(e event.Event) (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	start := w.cursor
+	if e.Range != nil {
+		if e.Range.To != nil {
+			return 0, fmt.Errorf("range not allowed for %s", e.CmdName)
+		}
+		if pos, ok := e.Range.From.(event.Absolute); ok && pos.Offset >= w.length {
+			start = w.length // allow appending to the end of the buffer
+		} else {
+			var err error
+			if start, err = w.positionToOffset(e.Range.From); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if e.Bang {
+		w.buffer.Overwrite(start, e.Buffer)
+	} else {
+		w.buffer.Paste(start, e.Buffer)
+	}
+	l, _ := e.Buffer.Len()
+	w.length, _ = w.buffer.Len()
+	w.cursor = mathutil.MinInt64(start, mathutil.MaxInt64(w.length, 1)-1)
+	w.updateTick()
+	w.history.Push(w.buffer, w.offset, w.cursor, w.changedTick)
+	w.prevChanged = true
+	return l, nil
+}
+
+// search the pattern with the offset following the separator.
+func (w *window) search(str string, sep rune, forward bool) error {
+	pattern, offset, err := searcher.SplitOffset(str, sep)
+	if err != nil {
+		return err
+	}
+	target, _, err := searcher.DecodeSearchPattern(pattern)
+	if err != nil {
+		return err
+	}
+	cursor := w.cursor
+	ch := w.startSearch(cursor, -1, pattern, forward)
+	go func() {
+		x, wrapped := w.waitSearch(ch, cursor, pattern, forward, nil)
+		w.mu.Lock()
+		w.searchProgress = -1
+		w.mu.Unlock()
+		switch x := x.(type) {
+		case error:
+			w.eventCh <- event.Event{Type: event.Info, Error: x}
+		case int64:
+			w.mu.Lock()
+			if offset.End {
+				x += int64(len(target)) - 1
+			}
+			w.cursor = mathutil.MaxInt64(0, mathutil.MinInt64(x+offset.N, w.length-1))
+			w.mu.Unlock()
+			if !wrapped {
+				w.redrawCh <- struct{}{}
+			} else if forward {
+				w.eventCh <- event.Event{Type: event.Info, Error: errors.New("search hit BOTTOM, continuing at TOP")}
+			} else {
+				w.eventCh <- event.Event{Type: event.Info, Error: errors.New("search hit TOP, continuing at BOTTOM")}
+			}
+		default:
+			w.redrawCh <- struct{}{}
+		}
+	}()
+	return nil
+}
+
+// startSearch starts the search from the cursor. The search stops at the
+// limit, or at the end of the buffer if the limit is negative.
+func (w *window) startSearch(cursor, limit int64, pattern string, forward bool) <-chan interface{} {
 	if w.searchTick != w.changedTick {
 		w.searcher.Abort()
 		w.searcher = searcher.NewSearcher(w.buffer)
 		w.searchTick = w.changedTick
 	}
-	ch := w.searcher.Search(w.cursor, str, forward)
+	if limit < 0 {
+		return w.searcher.Search(cursor, pattern, forward)
+	}
+	return w.searcher.SearchUntil(cursor, limit, pattern, forward)
+}
+
+// waitSearch waits for the result of the search. When the pattern is not
+// found, the search continues from the other end of the buffer up to the
+// cursor if wrapscan is set and the search is still active. The progress of the search is
+// shown in the status line unless it is an incremental search.
+func (w *window) waitSearch(
+	ch <-chan interface{}, cursor int64, pattern string, forward bool, active func() bool,
+) (interface{}, bool) {
+	x := w.receiveSearch(ch, cursor, forward, active == nil)
+	if err, ok := x.(error); !ok || !searcher.IsNotFound(err) {
+		return x, false
+	}
+	w.mu.Lock()
+	if active != nil && !active() {
+		w.mu.Unlock()
+		return nil, false
+	}
+	if !w.wrapScan {
+		w.mu.Unlock()
+		if forward {
+			return fmt.Errorf("search hit BOTTOM without match for: %s", pattern), false
+		}
+		return fmt.Errorf("search hit TOP without match for: %s", pattern), false
+	}
+	limit := cursor
+	cursor = -1
+	if !forward {
+		cursor = w.length
+	}
+	ch = w.startSearch(cursor, limit, pattern, forward)
+	w.mu.Unlock()
+	return w.receiveSearch(ch, cursor, forward, active == nil), true
+}
+
+// receiveSearch receives the result of the search. The progress of the
+// search is the position of the searched bytes in the buffer.
+func (w *window) receiveSearch(ch <-chan interface{}, cursor int64, forward, report bool) interface{} {
+	for x := range ch {
+		searched, ok := x.(searcher.Progress)
+		if !ok {
+			return x
+		}
+		if report {
+			w.mu.Lock()
+			pos := cursor + 1 + int64(searched)
+			if !forward {
+				pos = cursor - int64(searched)
+			}
+			w.searchProgress = int(mathutil.MaxInt64(0, mathutil.MinInt64(
+				pos*100/mathutil.MaxInt64(w.length, 1), 100)))
+			w.mu.Unlock()
+			w.redrawCh <- struct{}{}
+		}
+	}
+	return nil
+}
+
+// incSearch previews the match of the pattern typed in the cmdline. The
+// search starts from the cursor on starting the incremental search, and
+// the previous search is canceled on typing the pattern.
+func (w *window) incSearch(str string, sep rune, forward bool) {
+	if !w.incSearching {
+		w.incSearching, w.incSearchPos = true, position{w.cursor, w.offset}
+	}
+	w.cursor, w.offset = w.incSearchPos.cursor, w.incSearchPos.offset
+	w.matchStart, w.matchEnd = 0, 0
+	w.incSearchTick++
+	pattern, _, _ := searcher.SplitOffset(str, sep)
+	target, _, err := searcher.DecodeSearchPattern(pattern)
+	if err != nil || len(target) == 0 {
+		w.searcher.Abort()
+		return
+	}
+	tick := w.incSearchTick
+	active := func() bool {
+		return w.incSearching && w.incSearchTick == tick
+	}
+	cursor := w.cursor
+	ch := w.startSearch(cursor, -1, pattern, forward)
 	go func() {
-		select {
-		case x := <-ch:
-			switch x := x.(type) {
-			case error:
-				w.eventCh <- event.Event{Type: event.Info, Error: x}
-			case int64:
-				w.mu.Lock()
-				w.cursor = x
+		x, _ := w.waitSearch(ch, cursor, pattern, forward, active)
+		if x, ok := x.(int64); ok {
+			w.mu.Lock()
+			if !active() {
 				w.mu.Unlock()
-				w.redrawCh <- struct{}{}
+				return
 			}
+			w.cursor = x
+			w.matchStart, w.matchEnd = x, x+int64(len(target))
+			w.mu.Unlock()
+			w.redrawCh <- struct{}{}
 		}
 	}()
 }
 
+// exitIncSearch restores the cursor moved by the incremental search.
+func (w *window) exitIncSearch() {
+	if w.incSearching {
+		w.searcher.Abort()
+		w.cursor, w.offset = w.incSearchPos.cursor, w.incSearchPos.offset
+		w.incSearching, w.matchStart, w.matchEnd = false, 0, 0
+	}
+}
+
 func (w *window) abortSearch() {
 	if err := w.searcher.Abort(); err != nil {
 		w.eventCh <- event.Event{Type: event.Info, Error: err}
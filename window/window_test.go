@@ -2,13 +2,17 @@ package window
 
 import (
 	"bytes"
+	"errors"
+	"io/ioutil"
 	"math"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 
 	"github.com/itchyny/bed/event"
 	"github.com/itchyny/bed/mode"
+	"github.com/itchyny/bed/option"
 )
 
 func TestWindowState(t *testing.T) {
@@ -1273,6 +1277,57 @@ func TestWindowEventRuneText(t *testing.T) {
 	}
 }
 
+func TestWindowDisplay(t *testing.T) {
+	width, height := 16, 10
+	window, _ := newWindow(strings.NewReader(""), "test", "test", make(chan event.Event), make(chan struct{}))
+	window.setSize(width, height)
+	window.startInsert()
+
+	for _, testCase := range []struct {
+		display  string
+		input    string
+		expected string
+		digits   int
+	}{
+		{"binary", "0100100021", "\x48", 1},
+		{"binary", "10000001", "\x48\x81", 0},
+		{"octal", "401", "\x48\x81", 2},
+		{"octal", "0173777", "\x48\x81\x0f\xff", 0},
+		{"decimal", "3256", "\x48\x81\x0f\xff", 2},
+		{"decimal", "255072", "\x48\x81\x0f\xff\xff\x48", 0},
+		{"word32le", "ab0F", "\x48\x81\x0f\xff\xff\x48\xab", 1},
+	} {
+		if _, err := window.setDisplay(event.Event{Arg: testCase.display}); err != nil {
+			t.Errorf("setDisplay should not return error but got %v", err)
+		}
+		for _, ch := range testCase.input {
+			window.insertRune(mode.Insert, ch)
+		}
+		s, _ := window.state(width, height)
+		if got := string(s.Bytes[:s.Size]); got != testCase.expected {
+			t.Errorf("s.Bytes should be %q but got %q", testCase.expected, got)
+		}
+		if s.PendingDigits != testCase.digits {
+			t.Errorf("s.PendingDigits should be %d but got %d", testCase.digits, s.PendingDigits)
+		}
+		if s.Display.Name != testCase.display {
+			t.Errorf("s.Display should be %q but got %q", testCase.display, s.Display.Name)
+		}
+	}
+
+	if str, err := window.setDisplay(event.Event{}); str != "word32le" || err != nil {
+		t.Errorf("setDisplay should return the name but got %q, %v", str, err)
+	}
+	if _, err := window.setDisplay(event.Event{CmdName: "display", Arg: "hex binary"}); err == nil ||
+		err.Error() != "too many arguments for display" {
+		t.Errorf("setDisplay should return error but got %v", err)
+	}
+	if _, err := window.setDisplay(event.Event{Arg: "unknown"}); err == nil ||
+		err.Error() != "unknown display: unknown" {
+		t.Errorf("setDisplay should return error but got %v", err)
+	}
+}
+
 func TestWindowEventUndoRedo(t *testing.T) {
 	width, height := 16, 10
 	redrawCh := make(chan struct{})
@@ -1411,3 +1466,486 @@ func TestWindowWriteTo(t *testing.T) {
 		}
 	}
 }
+
+func TestWindowCursorGotoExpression(t *testing.T) {
+	r := strings.NewReader("MZ" + strings.Repeat("\x00", 0x3a) + "\x80\x00\x00\x00" + strings.Repeat("\x00", 0x100))
+	window, err := newWindow(r, "test", "test", make(chan event.Event), make(chan struct{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	window.setSize(16, 10)
+	window.cursorNext(mode.Normal, 0x10)
+	window.setMark('a')
+	for _, testCase := range []struct {
+		target   string
+		expected int64
+		err      string
+	}{
+		{"0x40 + u32le(0x3c)", 0xc0, ""},
+		{"'a * 2", 0x20, ""},
+		{"u32le(0x3c) / 0x10 * (1 + 1)", 0x10, ""},
+		{"$ * 2", 0x13f, ""},
+		{"'b", 0x13f, "mark not set: b"},
+		{"u32le(0x13e)", 0x13f, "u32le: cannot read 4 bytes at 0x13e"},
+	} {
+		pos, _ := event.ParsePos([]rune(testCase.target), 0)
+		err := window.cursorGoto(event.Event{Range: &event.Range{From: pos}})
+		if testCase.err != "" {
+			if err == nil || err.Error() != testCase.err {
+				t.Errorf("cursorGoto(%q) should return error %q but got %v", testCase.target, testCase.err, err)
+			}
+		} else if err != nil {
+			t.Errorf("err should be nil but got: %v", err)
+		}
+		if window.cursor != testCase.expected {
+			t.Errorf("cursorGoto(%q) should move cursor to %d but got %d", testCase.target, testCase.expected, window.cursor)
+		}
+	}
+}
+
+func TestWindowCursorGotoOffsetOrigin(t *testing.T) {
+	r := strings.NewReader(strings.Repeat("\x00", 0x100))
+	window, err := newWindow(r, "test", "test", make(chan event.Event), make(chan struct{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	window.setSize(16, 10)
+	window.setOptions(option.Options{OffsetOrigin: 0x1000})
+	window.cursorNext(mode.Normal, 0x10)
+	window.setMark('a')
+	for _, testCase := range []struct {
+		target   string
+		expected int64
+	}{
+		{"0x1020", 0x20},
+		{"0x1000 + 0x30", 0x30},
+		{"'a + 4", 0x14},
+		{". - 0x1000", 0x00},
+		{"$ - 0x10", 0xef},
+		{"0x20", 0x00},
+		{"0x2000", 0xff},
+	} {
+		pos, _ := event.ParsePos([]rune(testCase.target), 0)
+		if err := window.cursorGoto(event.Event{Range: &event.Range{From: pos}}); err != nil {
+			t.Errorf("err should be nil but got: %v", err)
+		}
+		if window.cursor != testCase.expected {
+			t.Errorf("cursorGoto(%q) should move cursor to %d but got %d", testCase.target, testCase.expected, window.cursor)
+		}
+	}
+}
+
+func TestWindowFill(t *testing.T) {
+	width, height := 16, 10
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	window, err := newWindow(strings.NewReader("Hello, world!"), "test", "test", eventCh, redrawCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window.setSize(width, height)
+	for _, testCase := range []struct {
+		event    event.Event
+		expected string
+		message  string
+		cursor   int64
+	}{
+		{event.Event{Arg: "0xDEADBEEF"}, "\xde\xad\xbe\xefo, world!", "4 (0x4) bytes filled", 0},
+		{event.Event{Range: &event.Range{From: event.Absolute{Offset: 5}, To: event.End{}}, Arg: `ab\0`},
+			"\xde\xad\xbe\xefoab\x00ab\x00ab", "8 (0x8) bytes filled", 5},
+		{event.Event{Range: &event.Range{From: event.Absolute{Offset: 2}, To: event.Absolute{Offset: 4}}, Arg: "inc 0xfe", Bang: true},
+			"\xde\xad\xfe\xff\x00\xbe\xefoab\x00ab\x00ab", "3 (0x3) bytes inserted", 2},
+		{event.Event{Range: &event.Range{From: event.End{}}, Arg: "xyz"},
+			"\xde\xad\xfe\xff\x00\xbe\xefoab\x00ab\x00axyz", "3 (0x3) bytes filled", 15},
+		{event.Event{Arg: "inc 256"}, "", "invalid start byte for fill: 256", 15},
+		{event.Event{Arg: "0xZZ"}, "", "invalid hex pattern: 0xZZ", 15},
+	} {
+		testCase.event.Type, testCase.event.CmdName, testCase.event.Mode = event.Fill, "fill", mode.Normal
+		window.emit(testCase.event)
+		if e := <-eventCh; e.Error.Error() != testCase.message {
+			t.Errorf("fill should emit message %q but got %q", testCase.message, e.Error.Error())
+		}
+		if testCase.expected == "" {
+			continue
+		}
+		s, _ := window.state(width, height)
+		if got := string(s.Bytes[:s.Size]); got != testCase.expected {
+			t.Errorf("s.Bytes should be %q but got %q", testCase.expected, got)
+		}
+		if s.Cursor != testCase.cursor {
+			t.Errorf("s.Cursor should be %d but got %d", testCase.cursor, s.Cursor)
+		}
+	}
+
+	window.emit(event.Event{Type: event.Undo, Mode: mode.Normal})
+	<-redrawCh
+	s, _ := window.state(width, height)
+	if expected := "\xde\xad\xfe\xff\x00\xbe\xefoab\x00ab\x00ab"; string(s.Bytes[:s.Size]) != expected {
+		t.Errorf("s.Bytes should be %q but got %q", expected, string(s.Bytes[:s.Size]))
+	}
+}
+
+func TestWindowFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skip filter test on Windows")
+	}
+	width, height := 16, 10
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	window, err := newWindow(strings.NewReader("Hello, world!"), "test", "test", eventCh, redrawCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window.setSize(width, height)
+	for _, testCase := range []struct {
+		event    event.Event
+		expected string
+		message  string
+	}{
+		{event.Event{Range: &event.Range{From: event.Absolute{Offset: 7}, To: event.Absolute{Offset: 11}}, Arg: "tr a-z A-Z"},
+			"Hello, WORLD!", "5 (0x5) bytes filtered"},
+		{event.Event{Range: &event.Range{From: event.Absolute{}, To: event.End{}}, Arg: "rev"},
+			"!DLROW ,olleH", "13 (0xd) bytes filtered"},
+		{event.Event{Range: &event.Range{From: event.Absolute{Offset: 1}}, Arg: "printf foo"},
+			"!fooLROW ,olleH", "3 (0x3) bytes filtered"},
+		{event.Event{Arg: "echo hello"}, "!fooLROW ,olleH", "hello"},
+		{event.Event{Arg: "echo error >&2; exit 1"}, "!fooLROW ,olleH", "echo error >&2; exit 1: error"},
+		{event.Event{Arg: ""}, "!fooLROW ,olleH", "an argument is required for !"},
+	} {
+		testCase.event.Type, testCase.event.CmdName, testCase.event.Mode = event.Filter, "!", mode.Normal
+		window.emit(testCase.event)
+		e := <-eventCh
+		for len(redrawCh) > 0 {
+			<-redrawCh
+		}
+		if e.Error.Error() != testCase.message {
+			t.Errorf("filter should emit message %q but got %q", testCase.message, e.Error.Error())
+		}
+		s, _ := window.state(width, height)
+		if got := string(s.Bytes[:s.Size]); got != testCase.expected {
+			t.Errorf("s.Bytes should be %q but got %q", testCase.expected, got)
+		}
+	}
+
+	window.emit(event.Event{Type: event.Filter, Range: &event.Range{From: event.Absolute{}}, Arg: "sleep 10", Mode: mode.Normal})
+	<-redrawCh
+	window.emit(event.Event{Type: event.AbortSearch, Mode: mode.Normal})
+	<-redrawCh
+	if e := <-eventCh; e.Error.Error() != "command is aborted" {
+		t.Errorf("filter should emit message %q but got %q", "command is aborted", e.Error.Error())
+	}
+
+	window.emit(event.Event{Type: event.Undo, Mode: mode.Normal})
+	<-redrawCh
+	s, _ := window.state(width, height)
+	if expected := "!DLROW ,olleH"; string(s.Bytes[:s.Size]) != expected {
+		t.Errorf("s.Bytes should be %q but got %q", expected, string(s.Bytes[:s.Size]))
+	}
+}
+
+func TestWindowHash(t *testing.T) {
+	width, height := 16, 10
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	window, err := newWindow(strings.NewReader("123456789abc"), "test", "test", eventCh, redrawCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window.setSize(width, height)
+	for _, testCase := range []struct {
+		event   event.Event
+		message string
+		yanked  string
+	}{
+		{event.Event{Arg: "crc32"}, "crc32: bdb0c0e4", ""},
+		{event.Event{Range: &event.Range{From: event.Absolute{}, To: event.Absolute{Offset: 8}}, Arg: "crc32"},
+			"crc32: cbf43926", ""},
+		{event.Event{Range: &event.Range{From: event.Absolute{Offset: 8}, To: event.Absolute{}}, Arg: "crc16", Bang: true},
+			"crc16: bb3d", "\xbb\x3d"},
+		{event.Event{Range: &event.Range{From: event.Absolute{Offset: 9}}, Arg: " md5 "},
+			"md5: 0cc175b9c0f1b6a831c399e269772661", ""},
+		{event.Event{Arg: "sha3"}, "invalid argument for hash: sha3", ""},
+		{event.Event{}, "an argument is required for hash", ""},
+	} {
+		testCase.event.Type, testCase.event.CmdName, testCase.event.Mode = event.Hash, "hash", mode.Normal
+		window.emit(testCase.event)
+		e := <-eventCh
+		for len(redrawCh) > 0 {
+			<-redrawCh
+		}
+		if testCase.yanked != "" {
+			if e.Type != event.Hashed || e.Buffer == nil {
+				t.Errorf("hash should emit hashed event with the digest but got %v", e)
+			} else if bs, _ := ioutil.ReadAll(e.Buffer); string(bs) != testCase.yanked {
+				t.Errorf("hash should yank %q but got %q", testCase.yanked, string(bs))
+			}
+		} else if e.Type == event.Hashed && e.Buffer != nil {
+			t.Errorf("hash should not yank the digest but got %v", e)
+		}
+		if e.Error == nil || e.Error.Error() != testCase.message {
+			t.Errorf("hash should emit message %q but got %v", testCase.message, e.Error)
+		}
+	}
+}
+
+func TestWindowChecksumFix(t *testing.T) {
+	width, height := 16, 10
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	window, err := newWindow(strings.NewReader("123456789\x00\x00\x00\x00xyz"), "test", "test", eventCh, redrawCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window.setSize(width, height)
+	for _, testCase := range []struct {
+		event    event.Event
+		expected string
+		message  string
+	}{
+		{event.Event{Arg: "crc32 data=0,8 store=9 le"},
+			"123456789\x26\x39\xf4\xcbxyz", "crc32: cbf43926 (le) stored at 0x9"},
+		{event.Event{Arg: "crc16 data=0,8 store=0xd width=3"},
+			"123456789\x26\x39\xf4\xcb\x00\xbb\x3d", "crc16: 00bb3d (be) stored at 0xd"},
+		{event.Event{Type: event.Undo},
+			"123456789\x26\x39\xf4\xcbxyz", ""},
+		{event.Event{Arg: "sha3 data=0,8 store=9"},
+			"123456789\x26\x39\xf4\xcbxyz", "invalid argument for checksum-fix: sha3"},
+		{event.Event{Arg: "crc32 data=0,8 store=9 foo"},
+			"123456789\x26\x39\xf4\xcbxyz", "invalid argument for checksum-fix: foo"},
+		{event.Event{Arg: "crc32 data=0 store=9"},
+			"123456789\x26\x39\xf4\xcbxyz", "invalid argument for checksum-fix: data=0"},
+		{event.Event{Arg: "crc32 store=9"},
+			"123456789\x26\x39\xf4\xcbxyz", "data range is required for checksum-fix"},
+		{event.Event{Arg: "crc32 data=0,8"},
+			"123456789\x26\x39\xf4\xcbxyz", "store offset is required for checksum-fix"},
+		{event.Event{Arg: "crc32 data=0,8 store=4"},
+			"123456789\x26\x39\xf4\xcbxyz", "checksum field overlaps the data range"},
+		{event.Event{Arg: "crc32 data=0,8 store=0xd"},
+			"123456789\x26\x39\xf4\xcbxyz", "checksum field out of range: 0xd"},
+		{event.Event{Arg: "crc32 data=0,8 store=0x20"},
+			"123456789\x26\x39\xf4\xcbxyz", "checksum field out of range: 0x20"},
+		{event.Event{Arg: "crc32 data=0,0x20 store=9"},
+			"123456789\x26\x39\xf4\xcbxyz", "checksum field out of range: 0x20"},
+		{event.Event{Range: &event.Range{From: event.Absolute{}}, Arg: "crc32 data=0,8 store=9"},
+			"123456789\x26\x39\xf4\xcbxyz", "range not allowed for checksum-fix"},
+		{event.Event{Type: event.Fill, Range: &event.Range{From: event.Absolute{}}, Arg: "0x30"},
+			"023456789\x26\x39\xf4\xcbxyz", "1 (0x1) bytes filled"},
+		{event.Event{},
+			"023456789\x65\x2d\x8f\xdc\x00\x2b\x30", "2 checksum fields updated"},
+	} {
+		if testCase.event.Type == event.Nop {
+			testCase.event.Type = event.ChecksumFix
+		}
+		testCase.event.CmdName, testCase.event.Mode = "checksum-fix", mode.Normal
+		window.emit(testCase.event)
+		if testCase.message == "" {
+			<-redrawCh
+		} else if e := <-eventCh; e.Error == nil || e.Error.Error() != testCase.message {
+			t.Errorf("checksum-fix should emit message %q but got %v", testCase.message, e.Error)
+		}
+		s, _ := window.state(width, height)
+		if got := string(s.Bytes[:s.Size]); got != testCase.expected {
+			t.Errorf("s.Bytes should be %q but got %q", testCase.expected, got)
+		}
+	}
+
+	window.emit(event.Event{Type: event.Undo, Mode: mode.Normal})
+	<-redrawCh
+	s, _ := window.state(width, height)
+	if expected, got := "023456789\x26\x39\xf4\xcbxyz", string(s.Bytes[:s.Size]); got != expected {
+		t.Errorf("checksum-fix should be undone at once: s.Bytes should be %q but got %q", expected, got)
+	}
+
+	window.emit(event.Event{Type: event.PageTop, Mode: mode.Normal})
+	<-redrawCh
+	window.emit(event.Event{Type: event.DeleteByte, Mode: mode.Normal})
+	<-eventCh
+	window.emit(event.Event{Type: event.ChecksumFix, CmdName: "checksum-fix", Mode: mode.Normal})
+	if e, expected := <-eventCh, "checksum field out of range: 0xd"; e.Error == nil || e.Error.Error() != expected {
+		t.Errorf("checksum-fix should emit message %q but got %v", expected, e.Error)
+	}
+	s, _ = window.state(width, height)
+	if expected, got := "23456789\x26\x39\xf4\xcbxyz", string(s.Bytes[:s.Size]); got != expected {
+		t.Errorf("checksum-fix should not update any field: s.Bytes should be %q but got %q", expected, got)
+	}
+}
+
+func TestWindowSearch(t *testing.T) {
+	width, height := 16, 10
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	window, err := newWindow(strings.NewReader("abcabcabc"), "test", "test", eventCh, redrawCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window.setSize(width, height)
+	for _, testCase := range []struct {
+		event    event.Event
+		wrapScan bool
+		cursor   int64
+		message  string
+	}{
+		{event.Event{Type: event.ExecuteSearch, Arg: "bc", Rune: '/'}, true, 1, ""},
+		{event.Event{Type: event.NextSearch, Arg: "bc/e", Rune: '/'}, true, 5, ""},
+		{event.Event{Type: event.NextSearch, Arg: "bc/s-1", Rune: '/'}, true, 6, ""},
+		{event.Event{Type: event.ExecuteSearch, Arg: "ABC\\c", Rune: '/'}, true, 0, "search hit BOTTOM, continuing at TOP"},
+		{event.Event{Type: event.ExecuteSearch, Arg: "bc?e+1", Rune: '?'}, true, 8, "search hit TOP, continuing at BOTTOM"},
+		{event.Event{Type: event.PreviousSearch, Arg: "ab", Rune: '?'}, false, 8, "search hit BOTTOM without match for: ab"},
+		{event.Event{Type: event.ExecuteSearch, Arg: "ab/x", Rune: '/'}, false, 8, "invalid search offset: x"},
+	} {
+		window.setOptions(option.Options{Encoding: "utf-8", WrapScan: testCase.wrapScan})
+		window.emit(testCase.event)
+		if testCase.message == "" {
+			<-redrawCh
+			<-redrawCh
+		} else if e := <-eventCh; e.Error == nil || e.Error.Error() != testCase.message {
+			t.Errorf("search should emit message %q but got %v", testCase.message, e.Error)
+		}
+		for len(redrawCh) > 0 {
+			<-redrawCh
+		}
+		s, _ := window.state(width, height)
+		if s.Cursor != testCase.cursor {
+			t.Errorf("s.Cursor should be %d but got %d", testCase.cursor, s.Cursor)
+		}
+	}
+}
+
+func TestWindowIncrementalSearch(t *testing.T) {
+	width, height := 16, 10
+	eventCh, redrawCh := make(chan event.Event, 10), make(chan struct{}, 10)
+	window, err := newWindow(strings.NewReader("abcabcabc"), "test", "test", eventCh, redrawCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window.setSize(width, height)
+	for _, testCase := range []struct {
+		event      event.Event
+		redraws    int
+		cursor     int64
+		matchStart int64
+		matchEnd   int64
+	}{
+		{event.Event{Type: event.IncrementalSearch, Arg: "bc", Rune: '/'}, 2, 1, 1, 3},
+		{event.Event{Type: event.IncrementalSearch, Arg: "bca", Rune: '/'}, 2, 1, 1, 4},
+		{event.Event{Type: event.IncrementalSearch, Arg: "x", Rune: '/'}, 1, 0, 0, 0},
+		{event.Event{Type: event.IncrementalSearch, Arg: "ca", Rune: '/'}, 2, 2, 2, 4},
+		{event.Event{Type: event.ExitCmdline}, 1, 0, 0, 0},
+		{event.Event{Type: event.IncrementalSearch, Arg: "cab", Rune: '/'}, 2, 2, 2, 5},
+		{event.Event{Type: event.ExecuteSearch, Arg: "cab", Rune: '/'}, 2, 2, 0, 0},
+	} {
+		window.emit(testCase.event)
+		for i := 0; i < testCase.redraws; i++ {
+			<-redrawCh
+		}
+		s, _ := window.state(width, height)
+		if s.Cursor != testCase.cursor {
+			t.Errorf("s.Cursor should be %d but got %d", testCase.cursor, s.Cursor)
+		}
+		if s.MatchStart != testCase.matchStart || s.MatchEnd != testCase.matchEnd {
+			t.Errorf("match should be [%d, %d) but got [%d, %d)",
+				testCase.matchStart, testCase.matchEnd, s.MatchStart, s.MatchEnd)
+		}
+	}
+}
+
+func TestWindowInsertLiteral(t *testing.T) {
+	r := strings.NewReader("")
+	width, height := 16, 10
+	window, _ := newWindow(r, "test", "test", make(chan event.Event), make(chan struct{}))
+	window.setSize(width, height)
+
+	window.startInsert()
+	for _, testCase := range []struct {
+		input    string
+		expected string
+		event    event.Event
+	}{
+		{"d255", "\xff", event.Event{}},
+		{"o101", "\xffA", event.Event{}},
+		{"b01000010", "\xffAB", event.Event{}},
+		{"x43", "\xffABC", event.Event{}},
+		{"65a", "\xffABCA", event.Event{}},
+		{"d300", "\xffABCA", event.Event{Type: event.Error, Error: errors.New("invalid byte literal: d300")}},
+		{"z", "\xffABCAz", event.Event{}},
+		{"x7", "\xffABCAz", event.Event{}},
+	} {
+		window.startLiteral()
+		var ev event.Event
+		for _, ch := range testCase.input {
+			if window.literal != 0 {
+				ev = window.insertLiteral(mode.Insert, ch)
+			} else {
+				window.insertRune(mode.Insert, ch)
+			}
+		}
+		if ev.Type != testCase.event.Type || ev.Type == event.Error && ev.Error.Error() != testCase.event.Error.Error() {
+			t.Errorf("insertLiteral should emit %+v but got %+v", testCase.event, ev)
+		}
+		s, _ := window.state(width, height)
+		if got := string(s.Bytes[:s.Size]); got != testCase.expected {
+			t.Errorf("s.Bytes should be %q but got %q", testCase.expected, got)
+		}
+	}
+	window.backspace(mode.Insert)
+	window.backspace(mode.Insert)
+	window.insertRune(mode.Insert, 'a')
+	window.insertRune(mode.Insert, '0')
+	s, _ := window.state(width, height)
+	if expected := "\xffABCAz\xa0"; string(s.Bytes[:s.Size]) != expected {
+		t.Errorf("s.Bytes should be %q but got %q", expected, string(s.Bytes[:s.Size]))
+	}
+
+	window.focusText = true
+	for _, testCase := range []struct {
+		encoding string
+		expected string
+	}{
+		{"utf-16le", "a\x00\x42\x30=\xd8\x00\xde"},
+		{"utf-16be", "\x00a\x30\x42\xd8=\xde\x00"},
+	} {
+		window.startLiteral()
+		if ev := window.insertLiteral(mode.Insert, 'e'); ev.Type != event.Set ||
+			ev.Arg != "encoding="+testCase.encoding+" encoding?" {
+			t.Errorf("insertLiteral should emit encoding %q but got %+v", testCase.encoding, ev)
+		}
+		s, _ := window.state(width, height)
+		prev := s.Size
+		for _, ch := range "aあ\U0001f600" {
+			window.insertRune(mode.Insert, ch)
+		}
+		s, _ = window.state(width, height)
+		if got := string(s.Bytes[prev:s.Size]); got != testCase.expected {
+			t.Errorf("s.Bytes should be %q but got %q", testCase.expected, got)
+		}
+	}
+
+	for _, testCase := range []struct {
+		encoding string
+		input    string
+		expected string
+		err      string
+	}{
+		{"utf-8", "aあ\U0001f600", "aあ\U0001f600", ""},
+		{"latin1", "Aé", "A\xe9", ""},
+		{"ebcdic", "Az", "\xc1\xa9", ""},
+		{"shift-jis", "aあ", "a\x82\xa0", ""},
+		{"cp437", "aあ", "a", "cannot encode 'あ' in cp437"},
+	} {
+		window.setOptions(option.Options{Encoding: testCase.encoding})
+		s, _ := window.state(width, height)
+		prev := s.Size
+		var err error
+		for _, ch := range testCase.input {
+			if _, err = window.insertRune(mode.Insert, ch); err != nil {
+				break
+			}
+		}
+		if testCase.err != "" {
+			if err == nil || err.Error() != testCase.err {
+				t.Errorf("insertRune should return error %q but got %v", testCase.err, err)
+			}
+		} else if err != nil {
+			t.Errorf("insertRune should not return error but got %v", err)
+		}
+		s, _ = window.state(width, height)
+		if got := string(s.Bytes[prev:s.Size]); got != testCase.expected {
+			t.Errorf("s.Bytes should be %q but got %q", testCase.expected, got)
+		}
+	}
+}